@@ -1,6 +1,8 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 
@@ -8,7 +10,9 @@ import (
 
 	"github.com/virsavik/alchemist-template/cmd/banner"
 	"github.com/virsavik/alchemist-template/pkg/config"
+	"github.com/virsavik/alchemist-template/pkg/di"
 	"github.com/virsavik/alchemist-template/pkg/system"
+	"github.com/virsavik/alchemist-template/pkg/waiter"
 	"github.com/virsavik/alchemist-template/users"
 )
 
@@ -17,8 +21,29 @@ type monolith struct {
 	modules []system.Module
 }
 
+var diGraph = flag.String("di-graph", "", "print the dependency graph as `dot` or `json` after starting up the modules, then exit. "+
+	"The database is neither connected nor migrated, the configuration must still be valid")
+
 func main() {
-	banner.Show()
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: serverd [flags]\n       serverd migrate <command>\n\nflags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// Migrate the database with the migrations embedded into the binary, then exit
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(flag.Args()[1:]); err != nil {
+			fmt.Printf("alchemist-template migrate failed: %s\n", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Keep the output of the dependency graph clean
+	if *diGraph == "" {
+		banner.Show()
+	}
 
 	if err := run(); err != nil {
 		fmt.Printf("alchemist-template exitted abnormally: %s\n", err.Error())
@@ -32,7 +57,13 @@ func run() error {
 		return err
 	}
 
-	s, err := system.New(cfg)
+	// The dependency graph is printed without connecting to the database nor migrating it
+	var opts []system.Option
+	if *diGraph != "" {
+		opts = append(opts, system.Offline())
+	}
+
+	s, err := system.New(cfg, opts...)
 	if err != nil {
 		return err
 	}
@@ -49,12 +80,22 @@ func run() error {
 		return err
 	}
 
+	// Fail the startup when the module dependencies are wired incorrectly
+	if err = m.Container().Validate(); err != nil {
+		return err
+	}
+
+	if *diGraph != "" {
+		return printGraph(m.Container().Graph(), *diGraph)
+	}
+
 	fmt.Println("started alchemist-template application")
 	defer fmt.Println("stopped alchemist-template application")
 
-	m.Waiter().Add(
-		m.WaitForWeb,
-	)
+	m.Waiter().Add(m.WaitForWeb, waiter.TaskName("web server"))
+	m.Waiter().Add(m.WaitForRPC, waiter.TaskName("rpc server"))
+	m.Waiter().Add(m.WaitForAdmin, waiter.TaskName("admin server"))
+	m.Waiter().Add(m.WaitForStream, waiter.TaskName("event stream"))
 
 	//go func() {
 	//	for {
@@ -65,16 +106,25 @@ func run() error {
 	//	}
 	//}()
 
-	return m.Waiter().Wait()
+	err = m.Waiter().Wait()
+	fmt.Print(m.Waiter().Report())
+
+	return err
 }
 
 func (m *monolith) startupModules() error {
-	for _, module := range m.modules {
-		ctx := m.Waiter().Context()
-		if err := module.Startup(ctx, m); err != nil {
-			return err
-		}
-	}
+	return system.StartupModules(m.Waiter().Context(), m, m.modules...)
+}
 
-	return nil
+func printGraph(g di.Graph, format string) error {
+	switch format {
+	case "dot":
+		return g.WriteDOT(os.Stdout)
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(g)
+	default:
+		return fmt.Errorf("unknown dependency graph format `%s`", format)
+	}
 }
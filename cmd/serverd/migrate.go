@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/virsavik/alchemist-template/data"
+	"github.com/virsavik/alchemist-template/pkg/backoff"
+	"github.com/virsavik/alchemist-template/pkg/config"
+	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/migrate"
+	"github.com/virsavik/alchemist-template/pkg/postgres"
+)
+
+const migrateUsage = `usage: serverd migrate <command>
+
+commands:
+  up [N]         apply the next N migrations, every pending migration by default
+  down [N]       revert the last N migrations, the last one by default
+  status         print the version of the database and the applied migrations
+  goto V         apply or revert the migrations until the version V
+  force V        set the version V without migrating, once a failed migration is fixed by hand`
+
+var errMigrateUsage = errors.New("invalid migrate command")
+
+// runMigrate runs the migrate subcommand with the migrations embedded into the binary
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		return errMigrateUsage
+	}
+
+	cfg, err := config.ReadConfigFromEnv()
+	if err != nil {
+		return err
+	}
+
+	log, err := logger.New(logger.Config{Environment: cfg.Environment})
+	if err != nil {
+		return err
+	}
+	defer log.Flush()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := postgres.Open(cfg.PG)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	connectCtx, cancel := context.WithTimeout(ctx, cfg.PG.ConnectTimeout)
+	defer cancel()
+
+	if err = postgres.Connect(connectCtx, db, backoff.Exponential{}, log.Named("db")); err != nil {
+		return err
+	}
+
+	m, err := migrate.New(db, data.Migrations(), migrate.WithLogger(log.Named("migrate")))
+	if err != nil {
+		return err
+	}
+
+	cmd, arg := args[0], ""
+	if len(args) > 1 {
+		arg = args[1]
+	}
+
+	switch cmd {
+	case "up":
+		n, err := countArg(arg, 0)
+		if err != nil {
+			return err
+		}
+		return m.Up(ctx, n)
+	case "down":
+		n, err := countArg(arg, 1)
+		if err != nil {
+			return err
+		}
+		return m.Down(ctx, n)
+	case "goto", "force":
+		if arg == "" {
+			fmt.Println(migrateUsage)
+			return errMigrateUsage
+		}
+
+		version, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("version `%s` is invalid", arg)
+		}
+
+		if cmd == "goto" {
+			return m.Goto(ctx, version)
+		}
+		return m.Force(ctx, version)
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printStatus(status)
+	default:
+		fmt.Println(migrateUsage)
+		return errMigrateUsage
+	}
+}
+
+// countArg returns the number of migrations of the argument, or the default value when it is not set
+func countArg(arg string, defaultValue int) (int, error) {
+	if arg == "" {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.Atoi(arg)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("number of migrations `%s` is invalid", arg)
+	}
+
+	return n, nil
+}
+
+func printStatus(status migrate.Status) error {
+	fmt.Printf("version: %d, dirty: %t\n\n", status.Version, status.Dirty)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+	for _, mg := range status.Migrations {
+		fmt.Fprintf(w, "%d\t%s\t%t\n", mg.Version, mg.Name, mg.Applied)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,21 @@
+// Package data embeds the data files of the application into the binary
+package data
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed migrations/*.sql
+var files embed.FS
+
+// Migrations returns the database migrations, named <version>_<title>.up.sql and
+// <version>_<title>.down.sql, see migrate.New
+func Migrations() fs.FS {
+	sub, err := fs.Sub(files, "migrations")
+	if err != nil {
+		panic(err)
+	}
+
+	return sub
+}
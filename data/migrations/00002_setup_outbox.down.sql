@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "dispatched_on_outbox";
+DROP INDEX IF EXISTS "pending_on_outbox";
+DROP TABLE IF EXISTS "outbox";
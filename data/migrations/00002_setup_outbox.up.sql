@@ -0,0 +1,16 @@
+--
+-- OUTBOX table
+--
+CREATE TABLE IF NOT EXISTS "outbox" (
+    "id"            VARCHAR(32) PRIMARY KEY,
+    "name"          VARCHAR(255) NOT NULL,
+    "payload"       JSONB NOT NULL,
+    "trace_context" JSONB NOT NULL DEFAULT '{}',
+    "occurred_at"   TIMESTAMPTZ NOT NULL,
+    "created_at"    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    "dispatched_at" TIMESTAMPTZ NULL,
+    "attempts"      INT NOT NULL DEFAULT 0,
+    "last_error"    TEXT NULL
+);
+CREATE INDEX IF NOT EXISTS "pending_on_outbox" ON "outbox"("created_at") WHERE "dispatched_at" IS NULL;
+CREATE INDEX IF NOT EXISTS "dispatched_on_outbox" ON "outbox"("dispatched_at") WHERE "dispatched_at" IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "unique_key_on_jobs";
+DROP INDEX IF EXISTS "running_on_jobs";
+DROP INDEX IF EXISTS "pending_on_jobs";
+DROP TABLE IF EXISTS "jobs";
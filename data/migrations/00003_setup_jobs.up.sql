@@ -0,0 +1,23 @@
+--
+-- JOBS table
+--
+CREATE TABLE IF NOT EXISTS "jobs" (
+    "id"            VARCHAR(32) PRIMARY KEY,
+    "kind"          VARCHAR(255) NOT NULL,
+    "args"          JSONB NOT NULL,
+    "trace_context" JSONB NOT NULL DEFAULT '{}',
+    "unique_key"    VARCHAR(255) NULL,
+    "state"         VARCHAR(16) NOT NULL DEFAULT 'pending',
+    "attempts"      INT NOT NULL DEFAULT 0,
+    "max_attempts"  INT NOT NULL,
+    "run_at"        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    "locked_until"  TIMESTAMPTZ NULL,
+    "last_error"    TEXT NULL,
+    "created_at"    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    "updated_at"    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    "completed_at"  TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS "pending_on_jobs" ON "jobs"("run_at") WHERE "state" = 'pending';
+CREATE INDEX IF NOT EXISTS "running_on_jobs" ON "jobs"("locked_until") WHERE "state" = 'running';
+CREATE UNIQUE INDEX IF NOT EXISTS "unique_key_on_jobs" ON "jobs"("kind", "unique_key")
+    WHERE "unique_key" IS NOT NULL AND "state" IN ('pending', 'running');
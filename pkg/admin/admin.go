@@ -0,0 +1,205 @@
+package admin
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/virsavik/alchemist-template/pkg/config"
+	"github.com/virsavik/alchemist-template/pkg/rest/httpio"
+	"github.com/virsavik/alchemist-template/pkg/waiter"
+)
+
+// Route representing a route registered on a router with the names of its middlewares, from the
+// outermost to the innermost
+type Route struct {
+	Method      string   `json:"method"`
+	Pattern     string   `json:"pattern"`
+	Middlewares []string `json:"middlewares"`
+}
+
+// Task representing the status of a wait function of the waiter
+type Task struct {
+	Name     string    `json:"name"`
+	Policy   string    `json:"policy"`
+	State    string    `json:"state"`
+	Restarts int       `json:"restarts"`
+	LastErr  string    `json:"lastError,omitempty"`
+	Started  time.Time `json:"started"`
+}
+
+// BuildInfo representing the build information of the binary
+type BuildInfo struct {
+	GoVersion string            `json:"goVersion"`
+	Path      string            `json:"path"`
+	Version   string            `json:"version"`
+	Settings  map[string]string `json:"settings"`
+}
+
+// Mount mounts the admin endpoints on the router:
+//
+//	/debug/pprof/*  the runtime profiles of net/http/pprof
+//	/debug/vars     the variables of expvar
+//	/buildinfo      the build information of the binary
+//	/routes         the routes of the public router
+//	/tasks          the status of the wait functions
+//	/config         the configuration without its secrets
+//
+// The admin endpoints expose the internals of the application, they must not be served on the
+// public listener.
+func Mount(r chi.Router, routes chi.Routes, w waiter.Waiter, cfg config.AppConfig) {
+	r.HandleFunc("/debug/pprof/*", pprof.Index)
+	r.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	r.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	r.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	r.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	r.Handle("/debug/vars", expvar.Handler())
+
+	r.Get("/buildinfo", BuildInfoHandler())
+	r.Get("/routes", RoutesHandler(routes))
+	r.Get("/tasks", TasksHandler(w))
+	r.Get("/config", ConfigHandler(cfg))
+}
+
+// BuildInfoHandler serves the build information embedded in the binary
+func BuildInfoHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bi, ok := debug.ReadBuildInfo()
+		if !ok {
+			httpio.WriteJSON(w, r, httpio.Response[httpio.Message]{
+				Status: http.StatusNotFound,
+				Body:   httpio.Message{Code: "not_found", Desc: "build info is not available"},
+			})
+			return
+		}
+
+		info := BuildInfo{
+			GoVersion: bi.GoVersion,
+			Path:      bi.Path,
+			Version:   bi.Main.Version,
+			Settings:  make(map[string]string, len(bi.Settings)),
+		}
+		for _, s := range bi.Settings {
+			info.Settings[s.Key] = s.Value
+		}
+
+		httpio.WriteJSON(w, r, httpio.Response[BuildInfo]{
+			Status: http.StatusOK,
+			Body:   info,
+		})
+	}
+}
+
+// RoutesHandler serves the routes registered on the router, sorted by pattern and method
+func RoutesHandler(routes chi.Routes) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rs, err := Routes(routes)
+		if err != nil {
+			httpio.WriteJSON(w, r, httpio.Response[httpio.Message]{
+				Status: http.StatusInternalServerError,
+				Body:   httpio.Message{Code: "internal_error", Desc: err.Error()},
+			})
+			return
+		}
+
+		httpio.WriteJSON(w, r, httpio.Response[[]Route]{
+			Status: http.StatusOK,
+			Body:   rs,
+		})
+	}
+}
+
+// TasksHandler serves the status of the wait functions of the waiter
+func TasksHandler(wt waiter.Waiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		statuses := wt.Tasks()
+
+		tasks := make([]Task, 0, len(statuses))
+		for _, s := range statuses {
+			t := Task{
+				Name:     s.Name,
+				Policy:   s.Policy.String(),
+				State:    s.State.String(),
+				Restarts: s.Restarts,
+				Started:  s.Started,
+			}
+			if s.LastErr != nil {
+				t.LastErr = s.LastErr.Error()
+			}
+
+			tasks = append(tasks, t)
+		}
+
+		httpio.WriteJSON(w, r, httpio.Response[[]Task]{
+			Status: http.StatusOK,
+			Body:   tasks,
+		})
+	}
+}
+
+// ConfigHandler serves the effective configuration, its secrets are redacted
+func ConfigHandler(cfg config.AppConfig) http.HandlerFunc {
+	redacted := cfg.Redacted()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		httpio.WriteJSON(w, r, httpio.Response[config.AppConfig]{
+			Status: http.StatusOK,
+			Body:   redacted,
+		})
+	}
+}
+
+// Routes lists the routes registered on the router and its sub routers
+func Routes(routes chi.Routes) ([]Route, error) {
+	var rs []Route
+
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		names := make([]string, 0, len(middlewares))
+		for _, mw := range middlewares {
+			names = append(names, funcName(mw))
+		}
+
+		rs = append(rs, Route{
+			Method:      method,
+			Pattern:     strings.ReplaceAll(route, "/*/", "/"),
+			Middlewares: names,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].Pattern != rs[j].Pattern {
+			return rs[i].Pattern < rs[j].Pattern
+		}
+		return rs[i].Method < rs[j].Method
+	})
+
+	return rs, nil
+}
+
+// funcName returns the name of the function without its package path, e.g. middleware.Logger.func1
+func funcName(fn any) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "unknown"
+	}
+
+	name := f.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return name
+}
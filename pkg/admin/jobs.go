@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/virsavik/alchemist-template/pkg/jobs"
+	"github.com/virsavik/alchemist-template/pkg/rest/httpio"
+)
+
+const defaultDeadJobsLimit = 100
+
+// DeadJobs the dead jobs of the job queue, see jobs.Processor
+type DeadJobs interface {
+	Dead(ctx context.Context, limit int) ([]jobs.Job, error)
+	Replay(ctx context.Context, id string) error
+}
+
+// MountJobs mounts the endpoints listing and replaying the dead jobs:
+//
+//	GET  /jobs/dead          the most recently dead jobs, up to the limit query, 100 by default
+//	POST /jobs/{id}/replay   enqueues the dead job again with its attempts reset
+//
+// The endpoints change the behavior of the application, the router must authenticate the requests.
+func MountJobs(r chi.Router, dead DeadJobs) {
+	r.Get("/jobs/dead", DeadJobsHandler(dead))
+	r.Post("/jobs/{id}/replay", ReplayJobHandler(dead))
+}
+
+// DeadJobsHandler serves the most recently dead jobs
+func DeadJobsHandler(dead DeadJobs) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := httpio.URLQuery[int](r, "limit")
+		if limit <= 0 {
+			limit = defaultDeadJobsLimit
+		}
+
+		js, err := dead.Dead(r.Context(), limit)
+		if err != nil {
+			httpio.WriteJSON(w, r, httpio.Response[httpio.Message]{
+				Status: http.StatusInternalServerError,
+				Body:   httpio.Message{Code: "internal_error", Desc: err.Error()},
+			})
+			return
+		}
+
+		httpio.WriteJSON(w, r, httpio.Response[[]jobs.Job]{
+			Status: http.StatusOK,
+			Body:   js,
+		})
+	}
+}
+
+// ReplayJobHandler enqueues the dead job of the id again
+func ReplayJobHandler(dead DeadJobs) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := dead.Replay(r.Context(), chi.URLParam(r, "id"))
+		switch {
+		case err == nil:
+			httpio.WriteJSON(w, r, httpio.Response[httpio.Message]{
+				Status: http.StatusOK,
+				Body:   httpio.Message{Code: "job_replayed", Desc: "job is enqueued again"},
+			})
+		case errors.Is(err, jobs.ErrNotFound):
+			httpio.WriteJSON(w, r, httpio.Response[httpio.Message]{
+				Status: http.StatusNotFound,
+				Body:   httpio.Message{Code: "job_not_found", Desc: err.Error()},
+			})
+		case errors.Is(err, jobs.ErrDuplicated):
+			httpio.WriteJSON(w, r, httpio.Response[httpio.Message]{
+				Status: http.StatusConflict,
+				Body:   httpio.Message{Code: "job_duplicated", Desc: err.Error()},
+			})
+		default:
+			httpio.WriteJSON(w, r, httpio.Response[httpio.Message]{
+				Status: http.StatusInternalServerError,
+				Body:   httpio.Message{Code: "internal_error", Desc: err.Error()},
+			})
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/virsavik/alchemist-template/pkg/jobs"
+)
+
+type fakeDeadJobs struct {
+	replayErr error
+	replayed  []string
+}
+
+func (f *fakeDeadJobs) Dead(ctx context.Context, limit int) ([]jobs.Job, error) {
+	return nil, nil
+}
+
+func (f *fakeDeadJobs) Replay(ctx context.Context, id string) error {
+	f.replayed = append(f.replayed, id)
+	return f.replayErr
+}
+
+func TestReplayJobHandler(t *testing.T) {
+	tcs := map[string]struct {
+		replayErr error
+		expStatus int
+	}{
+		"replayed": {
+			expStatus: http.StatusOK,
+		},
+		"not found": {
+			replayErr: fmt.Errorf("%w: no dead job", jobs.ErrNotFound),
+			expStatus: http.StatusNotFound,
+		},
+		"duplicated": {
+			replayErr: fmt.Errorf("%w: unique key", jobs.ErrDuplicated),
+			expStatus: http.StatusConflict,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			dead := &fakeDeadJobs{replayErr: tc.replayErr}
+			r := chi.NewRouter()
+			MountJobs(r, dead)
+			w := httptest.NewRecorder()
+
+			// When
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/jobs/1a2b/replay", nil))
+
+			// Then
+			require.Equal(t, tc.expStatus, w.Code)
+			require.Equal(t, []string{"1a2b"}, dead.replayed)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/rest/httpio"
+)
+
+// SetLevelRequest representing the request changing the level of a logger, the level is reverted
+// once the ttl elapses when it is set, e.g. "15m"
+type SetLevelRequest struct {
+	Level string `json:"level"`
+	TTL   string `json:"ttl"`
+}
+
+// MountLoggers mounts the endpoints listing and changing the levels of the named loggers:
+//
+//	GET /loggers         the levels of the loggers
+//	PUT /loggers/{name}  sets the level of the logger and of its children, "root" sets every logger
+//
+// The endpoints change the behavior of the application, the router must authenticate the requests.
+func MountLoggers(r chi.Router, levels *logger.Levels) {
+	r.Get("/loggers", LevelsHandler(levels))
+	r.Put("/loggers/{name}", SetLevelHandler(levels))
+}
+
+// LevelsHandler serves the levels of the named loggers
+func LevelsHandler(levels *logger.Levels) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		httpio.WriteJSON(w, r, httpio.Response[[]logger.LevelStatus]{
+			Status: http.StatusOK,
+			Body:   levels.List(),
+		})
+	}
+}
+
+// SetLevelHandler sets the level of the named logger, permanently or for the ttl of the request
+func SetLevelHandler(levels *logger.Levels) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := httpio.BindJSON[SetLevelRequest](r.Body)
+		if err != nil {
+			writeBadRequest(w, r, "invalid_request", err.Error())
+			return
+		}
+
+		var ttl time.Duration
+		if req.TTL != "" {
+			if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+				writeBadRequest(w, r, "invalid_ttl", "ttl is invalid")
+				return
+			}
+		}
+
+		if err = levels.Set(chi.URLParam(r, "name"), req.Level, ttl); err != nil {
+			if errors.Is(err, logger.ErrUnknownLogger) {
+				httpio.WriteJSON(w, r, httpio.Response[httpio.Message]{
+					Status: http.StatusNotFound,
+					Body:   httpio.Message{Code: "logger_not_found", Desc: err.Error()},
+				})
+				return
+			}
+
+			writeBadRequest(w, r, "invalid_level", err.Error())
+			return
+		}
+
+		httpio.WriteJSON(w, r, httpio.Response[[]logger.LevelStatus]{
+			Status: http.StatusOK,
+			Body:   levels.List(),
+		})
+	}
+}
+
+func writeBadRequest(w http.ResponseWriter, r *http.Request, code, desc string) {
+	httpio.WriteJSON(w, r, httpio.Response[httpio.Message]{
+		Status: http.StatusBadRequest,
+		Body:   httpio.Message{Code: code, Desc: desc},
+	})
+}
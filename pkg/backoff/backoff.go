@@ -0,0 +1,77 @@
+package backoff
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultInitial    = 100 * time.Millisecond
+	defaultMax        = 30 * time.Second
+	defaultMultiplier = 2
+	defaultJitter     = 0.2
+)
+
+// Exponential representing an exponential backoff with jitter, the zero value uses the defaults:
+// 100ms initial delay, 30s max delay, a multiplier of 2 and a jitter of 20%
+type Exponential struct {
+	// Initial the delay before the first retry
+	Initial time.Duration
+	// Max the max delay between two retries
+	Max time.Duration
+	// Multiplier the factor the delay grows by on each retry
+	Multiplier float64
+	// Jitter the fraction of the delay randomly added or removed, to avoid retrying in lockstep
+	Jitter float64
+}
+
+// Delay returns the delay before the retry number attempt, the first retry being 0
+func (b Exponential) Delay(attempt int) time.Duration {
+	initial, max, multiplier, jitter := b.Initial, b.Max, b.Multiplier, b.Jitter
+	if initial <= 0 {
+		initial = defaultInitial
+	}
+	if max <= 0 {
+		max = defaultMax
+	}
+	if multiplier < 1 {
+		multiplier = defaultMultiplier
+	}
+	if jitter <= 0 || jitter > 1 {
+		jitter = defaultJitter
+	}
+
+	delay := float64(initial) * math.Pow(multiplier, float64(attempt))
+	if delay > float64(max) || math.IsInf(delay, 0) {
+		delay = float64(max)
+	}
+
+	delay += delay * jitter * (rand.Float64()*2 - 1)
+
+	return time.Duration(delay)
+}
+
+// MaxDelay returns the max delay between two retries, without the jitter
+func (b Exponential) MaxDelay() time.Duration {
+	if b.Max <= 0 {
+		return defaultMax
+	}
+
+	return b.Max
+}
+
+// Wait waits for the delay before the retry number attempt, it returns the error of ctx when
+// ctx is done before the delay elapses
+func (b Exponential) Wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(b.Delay(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
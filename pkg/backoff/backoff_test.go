@@ -0,0 +1,56 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExponential_Delay(t *testing.T) {
+	b := Exponential{
+		Initial:    time.Second,
+		Max:        10 * time.Second,
+		Multiplier: 2,
+		Jitter:     0.1,
+	}
+
+	tcs := map[string]struct {
+		attempt int
+		expMin  time.Duration
+		expMax  time.Duration
+	}{
+		"first retry": {
+			attempt: 0,
+			expMin:  900 * time.Millisecond,
+			expMax:  1100 * time.Millisecond,
+		},
+		"third retry": {
+			attempt: 2,
+			expMin:  3600 * time.Millisecond,
+			expMax:  4400 * time.Millisecond,
+		},
+		"capped": {
+			attempt: 10,
+			expMin:  9 * time.Second,
+			expMax:  11 * time.Second,
+		},
+		"overflow": {
+			attempt: 5000,
+			expMin:  9 * time.Second,
+			expMax:  11 * time.Second,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// When
+			delay := b.Delay(tc.attempt)
+
+			// Then
+			require.GreaterOrEqual(t, delay, tc.expMin)
+			require.LessOrEqual(t, delay, tc.expMax)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+)
+
+// Reloader serves a TLS certificate loaded from files, the certificate is reloaded when the files
+// change so that a renewed certificate is served without restarting the servers
+//
+//	r, err := certs.NewReloader("tls.crt", "tls.key", logger.NewNoop())
+//	srv.TLSConfig = &tls.Config{GetCertificate: r.GetCertificate}
+type Reloader struct {
+	certFile string
+	keyFile  string
+	logger   logger.Logger
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	modTimes [2]time.Time
+}
+
+// NewReloader loads the certificate from the files, it fails when the certificate cannot be loaded
+func NewReloader(certFile, keyFile string, log logger.Logger) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   log,
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate returns the certificate last loaded, see tls.Config.GetCertificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// Reload loads the certificate from the files, the certificate last loaded is kept on error
+func (r *Reloader) Reload() error {
+	modTimes, err := r.modTimesOfFiles()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate `%s`: %w", r.certFile, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert, r.modTimes = &cert, modTimes
+
+	return nil
+}
+
+// Watch reloads the certificate each interval when the files changed, until ctx is done. A certificate
+// which cannot be loaded is logged and the certificate last loaded is served until the next change.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+
+			if err := r.Reload(); err != nil {
+				r.logger.Errorf(err, "reload certificate failed, keep serving the previous certificate")
+				continue
+			}
+
+			r.logger.Infof("certificate `%s` reloaded", r.certFile)
+		}
+	}
+}
+
+// changed reports whether the files were modified since the certificate was last loaded
+func (r *Reloader) changed() bool {
+	modTimes, err := r.modTimesOfFiles()
+	if err != nil {
+		// The files are being replaced, they are reloaded once they are both written
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return modTimes != r.modTimes
+}
+
+func (r *Reloader) modTimesOfFiles() ([2]time.Time, error) {
+	var rs [2]time.Time
+	for idx, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return rs, err
+		}
+		rs[idx] = info.ModTime()
+	}
+
+	return rs, nil
+}
@@ -0,0 +1,97 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+)
+
+// writeCert writes a self-signed certificate of the serial number to the files
+func writeCert(t *testing.T, certFile, keyFile string, serial int64) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	// The files are written within the resolution of the modification times
+	modTime := time.Now().Add(time.Duration(serial) * time.Second)
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func serialOf(t *testing.T, r *Reloader) int64 {
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+
+	return leaf.SerialNumber.Int64()
+}
+
+func TestReloader_Watch(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, 1)
+
+	r, err := NewReloader(certFile, keyFile, logger.NewNoop())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), serialOf(t, r))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = r.Watch(ctx, 10*time.Millisecond) }()
+
+	// When
+	writeCert(t, certFile, keyFile, 2)
+
+	// Then
+	require.Eventually(t, func() bool {
+		return serialOf(t, r) == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestReloader_ReloadInvalid(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, 1)
+
+	r, err := NewReloader(certFile, keyFile, logger.NewNoop())
+	require.NoError(t, err)
+
+	// When
+	require.NoError(t, os.WriteFile(certFile, []byte("not a certificate"), 0o600))
+	err = r.Reload()
+
+	// Then
+	require.Error(t, err)
+	require.Equal(t, int64(1), serialOf(t, r))
+}
@@ -10,25 +10,168 @@ import (
 	"time"
 )
 
+// defaultRPCPort the port of the gRPC server when RPC_PORT is not set
+const defaultRPCPort = 8085
+
+// defaultAdminPort the port of the admin server when ADMIN_PORT is not set
+const defaultAdminPort = 8086
+
+// defaultAdminHost the admin server listens on the loopback interface when ADMIN_HOST is not set,
+// so that it is not reachable from the outside of the host
+const defaultAdminHost = "127.0.0.1"
+
+// defaultAdminScope the scope granted to the operators of the admin server when ADMIN_SCOPE is not set
+const defaultAdminScope = "admin"
+
+// defaultLogSampling the number of entries with the same level and message logged each second before
+// sampling them when LOG_SAMPLING_INITIAL or LOG_SAMPLING_THEREAFTER are not set
+const defaultLogSampling = 100
+
+// defaultLogDebugTTL the time the loggers stay at the debug level after a SIGUSR1 when LOG_DEBUG_TTL is not set
+const defaultLogDebugTTL = 15 * time.Minute
+
+// The defaults of the postgres connection pool when the PG_* variables are not set
+const (
+	defaultPGMaxOpenConns    = 25
+	defaultPGMaxIdleConns    = 10
+	defaultPGConnMaxLifetime = 30 * time.Minute
+	defaultPGConnMaxIdleTime = 5 * time.Minute
+	defaultPGConnectTimeout  = 30 * time.Second
+)
+
+// The defaults of the web server when the WEB_* variables are not set
+const (
+	defaultWebHost              = "0.0.0.0"
+	defaultWebReadHeaderTimeout = 5 * time.Second
+	defaultWebReadTimeout       = 30 * time.Second
+	defaultWebWriteTimeout      = 30 * time.Second
+	defaultWebIdleTimeout       = 120 * time.Second
+	defaultWebMaxHeaderBytes    = 1 << 20
+)
+
+// defaultShutdownTimeout the time the application has to shut down when SHUTDOWN_TIMEOUT is not set
+const defaultShutdownTimeout = 30 * time.Second
+
 // PGConfig representing a postgres configuration
 type PGConfig struct {
 	URI string
+	// MaxOpenConns the max number of connections of the pool
+	MaxOpenConns int
+	// MaxIdleConns the max number of idle connections kept in the pool
+	MaxIdleConns int
+	// ConnMaxLifetime the time a connection is reused before being closed, e.g. to balance the
+	// connections across the replicas behind a proxy
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime the time a connection is idle before being closed
+	ConnMaxIdleTime time.Duration
+	// ConnectTimeout the time the application has to connect to the database on startup
+	ConnectTimeout time.Duration
+	// AutoMigrate applies the pending migrations on startup, e.g. in development
+	AutoMigrate bool
 }
 
 // WebConfig representing a web configuration
 type WebConfig struct {
 	Host string
 	Port string
+	// ReadHeaderTimeout the time the clients have to send the headers of a request
+	ReadHeaderTimeout time.Duration
+	// ReadTimeout the time the clients have to send a request, including its body
+	ReadTimeout time.Duration
+	// WriteTimeout the time the handlers have to write a response, from the end of the request headers
+	WriteTimeout time.Duration
+	// IdleTimeout the time an idle keep-alive connection is kept open
+	IdleTimeout time.Duration
+	// MaxHeaderBytes the max size of the headers of a request
+	MaxHeaderBytes int
+	// TLSCertFile and TLSKeyFile the certificate served over TLS, it is reloaded when the files change.
+	// The requests are served in plain text when they are not set.
+	TLSCertFile string
+	TLSKeyFile  string
+	// H2C serves HTTP/2 over plain text connections, e.g. for the internal traffic behind a proxy
+	// terminating TLS. It is ignored when TLS is enabled, HTTP/2 being negotiated over TLS.
+	H2C bool
+}
+
+// TLS reports whether the requests are served over TLS
+func (c WebConfig) TLS() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
 }
 
 func (c WebConfig) Address() string {
 	return fmt.Sprintf("%s%v", c.Host, c.Port)
 }
 
+// RPCConfig representing a gRPC server configuration
+type RPCConfig struct {
+	Host string
+	Port string
+}
+
+func (c RPCConfig) Address() string {
+	return fmt.Sprintf("%s%v", c.Host, c.Port)
+}
+
+// AdminConfig representing an admin server configuration
+type AdminConfig struct {
+	Host string
+	Port string
+	// Scope the scope of the token required to change the loggers and the jobs
+	Scope string
+}
+
+func (c AdminConfig) Address() string {
+	return fmt.Sprintf("%s%v", c.Host, c.Port)
+}
+
+// LogConfig representing a logger configuration
+type LogConfig struct {
+	// Level the initial level of the loggers, the default level of the environment when empty
+	Level string
+	// SamplingInitial the number of entries with the same level and message logged each second, the
+	// entries are not sampled when it is 0
+	SamplingInitial int
+	// SamplingThereafter every Thereafter entry is logged once SamplingInitial entries are logged
+	SamplingThereafter int
+	// DebugTTL the time the loggers stay at the debug level after a SIGUSR1
+	DebugTTL time.Duration
+}
+
+// The exporters of the traces
+const (
+	TracesExporterOTLP   = "otlp"
+	TracesExporterStdout = "stdout"
+	TracesExporterFile   = "file"
+	TracesExporterNone   = "none"
+)
+
+// The protocols of the OTLP exporter
+const (
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http/protobuf"
+)
+
 // OtelConfig representing an open telemetry configuration
 type OtelConfig struct {
 	ServiceName      string
+	ServiceVersion   string
 	ExporterEndpoint string
+	// TracesExporter the exporter of the traces, "otlp" when the exporter endpoint is set, "none" otherwise
+	TracesExporter string
+	// TracesFile the file the traces are written to with the "file" exporter
+	TracesFile string
+	// ExporterProtocol the protocol of the OTLP exporter, "grpc" or "http/protobuf"
+	ExporterProtocol string
+	// ExporterInsecure disables the TLS of the OTLP exporter
+	ExporterInsecure bool
+	// ExporterCertificate the CA certificate file verifying the OTLP endpoint, the system CAs when empty
+	ExporterCertificate string
+	// ExporterHeaders the headers sent with the OTLP exports, e.g. the API key of a vendor
+	ExporterHeaders map[string]string
+	// SamplerRatio the ratio of the root traces sampled, the traces of the sampled parents are always sampled
+	SamplerRatio float64
+	// ResourceAttributes the extra attributes of the resource, e.g. the region
+	ResourceAttributes map[string]string
 }
 
 // IAMConfig representing an identity configuration
@@ -42,9 +185,15 @@ type AppConfig struct {
 	Environment     string
 	PG              PGConfig
 	Web             WebConfig
+	RPC             RPCConfig
+	Admin           AdminConfig
+	Log             LogConfig
 	IAM             IAMConfig
 	Otel            OtelConfig
 	ShutdownTimeout time.Duration
+	// ShutdownDrainDelay the time the load balancers have to stop sending traffic once the readiness
+	// fails, before the servers stop accepting connections
+	ShutdownDrainDelay time.Duration
 }
 
 // ReadConfigFromEnv reads all environment variables, validates it and parses it into AppConfig struct
@@ -59,11 +208,114 @@ func ReadConfigFromEnv() (AppConfig, error) {
 		return AppConfig{}, errors.New("port is invalid")
 	}
 
+	webHost := defaultWebHost
+	if v := strings.TrimSpace(os.Getenv("APP_HOST")); v != "" {
+		webHost = v
+	}
+
+	webReadHeaderTimeout, err := durationFromEnv("WEB_READ_HEADER_TIMEOUT", defaultWebReadHeaderTimeout)
+	if err != nil || webReadHeaderTimeout <= 0 {
+		return AppConfig{}, errors.New("web read header timeout is invalid")
+	}
+
+	webReadTimeout, err := durationFromEnv("WEB_READ_TIMEOUT", defaultWebReadTimeout)
+	if err != nil || webReadTimeout < 0 {
+		return AppConfig{}, errors.New("web read timeout is invalid")
+	}
+
+	webWriteTimeout, err := durationFromEnv("WEB_WRITE_TIMEOUT", defaultWebWriteTimeout)
+	if err != nil || webWriteTimeout < 0 {
+		return AppConfig{}, errors.New("web write timeout is invalid")
+	}
+
+	webIdleTimeout, err := durationFromEnv("WEB_IDLE_TIMEOUT", defaultWebIdleTimeout)
+	if err != nil || webIdleTimeout < 0 {
+		return AppConfig{}, errors.New("web idle timeout is invalid")
+	}
+
+	webMaxHeaderBytes, err := intFromEnv("WEB_MAX_HEADER_BYTES", defaultWebMaxHeaderBytes)
+	if err != nil || webMaxHeaderBytes <= 0 {
+		return AppConfig{}, errors.New("web max header bytes is invalid")
+	}
+
+	webTLSCertFile := strings.TrimSpace(os.Getenv("WEB_TLS_CERT_FILE"))
+	webTLSKeyFile := strings.TrimSpace(os.Getenv("WEB_TLS_KEY_FILE"))
+	if (webTLSCertFile == "") != (webTLSKeyFile == "") {
+		return AppConfig{}, errors.New("web tls cert file and key file are both required")
+	}
+
+	var webH2C bool
+	if v := strings.TrimSpace(os.Getenv("WEB_H2C")); v != "" {
+		webH2C, err = strconv.ParseBool(v)
+		if err != nil {
+			return AppConfig{}, errors.New("web h2c is invalid")
+		}
+	}
+
+	rpcPort := defaultRPCPort
+	if v := strings.TrimSpace(os.Getenv("RPC_PORT")); v != "" {
+		rpcPort, err = strconv.Atoi(v)
+		if err != nil || (rpcPort < 0 || rpcPort > 9999) {
+			return AppConfig{}, errors.New("rpc port is invalid")
+		}
+	}
+
+	adminHost := defaultAdminHost
+	if v := strings.TrimSpace(os.Getenv("ADMIN_HOST")); v != "" {
+		adminHost = v
+	}
+
+	adminPort := defaultAdminPort
+	if v := strings.TrimSpace(os.Getenv("ADMIN_PORT")); v != "" {
+		adminPort, err = strconv.Atoi(v)
+		if err != nil || (adminPort < 0 || adminPort > 9999) {
+			return AppConfig{}, errors.New("admin port is invalid")
+		}
+	}
+
+	adminScope := defaultAdminScope
+	if v := strings.TrimSpace(os.Getenv("ADMIN_SCOPE")); v != "" {
+		adminScope = v
+	}
+
 	pgURI := strings.TrimSpace(os.Getenv("PG_URL"))
 	if pgURI == "" {
 		return AppConfig{}, errors.New("pg uri is required")
 	}
 
+	pgMaxOpenConns, err := intFromEnv("PG_MAX_OPEN_CONNS", defaultPGMaxOpenConns)
+	if err != nil || pgMaxOpenConns <= 0 {
+		return AppConfig{}, errors.New("pg max open conns is invalid")
+	}
+
+	pgMaxIdleConns, err := intFromEnv("PG_MAX_IDLE_CONNS", defaultPGMaxIdleConns)
+	if err != nil || pgMaxIdleConns < 0 || pgMaxIdleConns > pgMaxOpenConns {
+		return AppConfig{}, errors.New("pg max idle conns is invalid")
+	}
+
+	pgConnMaxLifetime, err := durationFromEnv("PG_CONN_MAX_LIFETIME", defaultPGConnMaxLifetime)
+	if err != nil || pgConnMaxLifetime < 0 {
+		return AppConfig{}, errors.New("pg conn max lifetime is invalid")
+	}
+
+	pgConnMaxIdleTime, err := durationFromEnv("PG_CONN_MAX_IDLE_TIME", defaultPGConnMaxIdleTime)
+	if err != nil || pgConnMaxIdleTime < 0 {
+		return AppConfig{}, errors.New("pg conn max idle time is invalid")
+	}
+
+	pgConnectTimeout, err := durationFromEnv("PG_CONNECT_TIMEOUT", defaultPGConnectTimeout)
+	if err != nil || pgConnectTimeout <= 0 {
+		return AppConfig{}, errors.New("pg connect timeout is invalid")
+	}
+
+	var pgAutoMigrate bool
+	if v := strings.TrimSpace(os.Getenv("PG_AUTO_MIGRATE")); v != "" {
+		pgAutoMigrate, err = strconv.ParseBool(v)
+		if err != nil {
+			return AppConfig{}, errors.New("pg auto migrate is invalid")
+		}
+	}
+
 	otelServiceName := strings.TrimSpace(os.Getenv("OTEL_SERVICE_NAME"))
 	if otelServiceName == "" {
 		log.Print("open telemetry service name have not been set")
@@ -74,6 +326,63 @@ func ReadConfigFromEnv() (AppConfig, error) {
 		log.Print("open telemetry exporter endpoint have not been set")
 	}
 
+	otelTracesExporter := strings.ToLower(strings.TrimSpace(os.Getenv("OTEL_TRACES_EXPORTER")))
+	switch otelTracesExporter {
+	case "":
+		otelTracesExporter = TracesExporterNone
+		if otelExporterEndpoint != "" {
+			otelTracesExporter = TracesExporterOTLP
+		}
+	case TracesExporterOTLP:
+		if otelExporterEndpoint == "" {
+			return AppConfig{}, errors.New("open telemetry exporter endpoint is required by the otlp exporter")
+		}
+	case TracesExporterStdout, TracesExporterFile, TracesExporterNone:
+	default:
+		return AppConfig{}, errors.New("open telemetry traces exporter is invalid")
+	}
+
+	otelTracesFile := strings.TrimSpace(os.Getenv("OTEL_TRACES_FILE"))
+	if otelTracesExporter == TracesExporterFile && otelTracesFile == "" {
+		return AppConfig{}, errors.New("open telemetry traces file is required by the file exporter")
+	}
+
+	otelExporterProtocol := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"))
+	switch otelExporterProtocol {
+	case "":
+		otelExporterProtocol = OTLPProtocolGRPC
+	case OTLPProtocolGRPC, OTLPProtocolHTTP:
+	default:
+		return AppConfig{}, errors.New("open telemetry exporter protocol is invalid")
+	}
+
+	// The exporter is insecure by default, the collector being a sidecar or on the private network
+	otelExporterInsecure := true
+	if v := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_INSECURE")); v != "" {
+		otelExporterInsecure, err = strconv.ParseBool(v)
+		if err != nil {
+			return AppConfig{}, errors.New("open telemetry exporter insecure is invalid")
+		}
+	}
+
+	otelExporterHeaders, err := mapFromEnv("OTEL_EXPORTER_OTLP_HEADERS")
+	if err != nil {
+		return AppConfig{}, errors.New("open telemetry exporter headers are invalid")
+	}
+
+	otelSamplerRatio := 1.0
+	if v := strings.TrimSpace(os.Getenv("OTEL_TRACES_SAMPLER_ARG")); v != "" {
+		otelSamplerRatio, err = strconv.ParseFloat(v, 64)
+		if err != nil || otelSamplerRatio < 0 || otelSamplerRatio > 1 {
+			return AppConfig{}, errors.New("open telemetry sampler ratio is invalid")
+		}
+	}
+
+	otelResourceAttributes, err := mapFromEnv("OTEL_RESOURCE_ATTRIBUTES")
+	if err != nil {
+		return AppConfig{}, errors.New("open telemetry resource attributes are invalid")
+	}
+
 	iamTenant := strings.TrimSpace(os.Getenv("IAM_TENANT"))
 	if iamTenant == "" {
 		log.Print("iam domain have not been set")
@@ -84,22 +393,148 @@ func ReadConfigFromEnv() (AppConfig, error) {
 		log.Print("iam audience have not been set")
 	}
 
+	logLevel := strings.ToLower(strings.TrimSpace(os.Getenv("LOG_LEVEL")))
+	switch logLevel {
+	case "", "debug", "info", "warn", "error":
+	default:
+		return AppConfig{}, errors.New("log level is invalid")
+	}
+
+	logSamplingInitial, logSamplingThereafter := defaultLogSampling, defaultLogSampling
+	if v := strings.TrimSpace(os.Getenv("LOG_SAMPLING_INITIAL")); v != "" {
+		logSamplingInitial, err = strconv.Atoi(v)
+		if err != nil || logSamplingInitial < 0 {
+			return AppConfig{}, errors.New("log sampling initial is invalid")
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("LOG_SAMPLING_THEREAFTER")); v != "" {
+		logSamplingThereafter, err = strconv.Atoi(v)
+		if err != nil || logSamplingThereafter < 0 {
+			return AppConfig{}, errors.New("log sampling thereafter is invalid")
+		}
+	}
+
+	logDebugTTL := defaultLogDebugTTL
+	if v := strings.TrimSpace(os.Getenv("LOG_DEBUG_TTL")); v != "" {
+		logDebugTTL, err = time.ParseDuration(v)
+		if err != nil || logDebugTTL <= 0 {
+			return AppConfig{}, errors.New("log debug ttl is invalid")
+		}
+	}
+
+	shutdownTimeout := defaultShutdownTimeout
+	if v := strings.TrimSpace(os.Getenv("SHUTDOWN_TIMEOUT")); v != "" {
+		shutdownTimeout, err = time.ParseDuration(v)
+		if err != nil || shutdownTimeout <= 0 {
+			return AppConfig{}, errors.New("shutdown timeout is invalid")
+		}
+	}
+
+	var shutdownDrainDelay time.Duration
+	if v := strings.TrimSpace(os.Getenv("SHUTDOWN_DRAIN_DELAY")); v != "" {
+		shutdownDrainDelay, err = time.ParseDuration(v)
+		if err != nil || shutdownDrainDelay < 0 {
+			return AppConfig{}, errors.New("shutdown drain delay is invalid")
+		}
+	}
+
 	return AppConfig{
 		Environment: environment,
 		Web: WebConfig{
+			Host:              webHost,
+			Port:              fmt.Sprintf(":%v", port),
+			ReadHeaderTimeout: webReadHeaderTimeout,
+			ReadTimeout:       webReadTimeout,
+			WriteTimeout:      webWriteTimeout,
+			IdleTimeout:       webIdleTimeout,
+			MaxHeaderBytes:    webMaxHeaderBytes,
+			TLSCertFile:       webTLSCertFile,
+			TLSKeyFile:        webTLSKeyFile,
+			H2C:               webH2C,
+		},
+		RPC: RPCConfig{
 			Host: "0.0.0.0",
-			Port: fmt.Sprintf(":%v", port),
+			Port: fmt.Sprintf(":%v", rpcPort),
+		},
+		Admin: AdminConfig{
+			Host:  adminHost,
+			Port:  fmt.Sprintf(":%v", adminPort),
+			Scope: adminScope,
+		},
+		Log: LogConfig{
+			Level:              logLevel,
+			SamplingInitial:    logSamplingInitial,
+			SamplingThereafter: logSamplingThereafter,
+			DebugTTL:           logDebugTTL,
 		},
 		Otel: OtelConfig{
-			ServiceName:      otelServiceName,
-			ExporterEndpoint: otelExporterEndpoint,
+			ServiceName:         otelServiceName,
+			ServiceVersion:      strings.TrimSpace(os.Getenv("OTEL_SERVICE_VERSION")),
+			ExporterEndpoint:    otelExporterEndpoint,
+			TracesExporter:      otelTracesExporter,
+			TracesFile:          otelTracesFile,
+			ExporterProtocol:    otelExporterProtocol,
+			ExporterInsecure:    otelExporterInsecure,
+			ExporterCertificate: strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_CERTIFICATE")),
+			ExporterHeaders:     otelExporterHeaders,
+			SamplerRatio:        otelSamplerRatio,
+			ResourceAttributes:  otelResourceAttributes,
 		},
 		IAM: IAMConfig{
 			Tenant:   iamTenant,
 			Audience: iamAudience,
 		},
 		PG: PGConfig{
-			URI: pgURI,
+			URI:             pgURI,
+			MaxOpenConns:    pgMaxOpenConns,
+			MaxIdleConns:    pgMaxIdleConns,
+			ConnMaxLifetime: pgConnMaxLifetime,
+			ConnMaxIdleTime: pgConnMaxIdleTime,
+			ConnectTimeout:  pgConnectTimeout,
+			AutoMigrate:     pgAutoMigrate,
 		},
+		ShutdownTimeout:    shutdownTimeout,
+		ShutdownDrainDelay: shutdownDrainDelay,
 	}, nil
 }
+
+// intFromEnv returns the integer of the environment variable, or the default value when it is not set
+func intFromEnv(key string, defaultValue int) (int, error) {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return defaultValue, nil
+	}
+
+	return strconv.Atoi(v)
+}
+
+// durationFromEnv returns the duration of the environment variable, or the default value when it is not set
+func durationFromEnv(key string, defaultValue time.Duration) (time.Duration, error) {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return defaultValue, nil
+	}
+
+	return time.ParseDuration(v)
+}
+
+// mapFromEnv returns the comma separated key=value pairs of the environment variable, e.g. "a=1,b=2"
+func mapFromEnv(key string) (map[string]string, error) {
+	rs := map[string]string{}
+
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return rs, nil
+	}
+
+	for _, pair := range strings.Split(v, ",") {
+		k, val, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("pair `%s` of %s is invalid", pair, key)
+		}
+
+		rs[strings.TrimSpace(k)] = strings.TrimSpace(val)
+	}
+
+	return rs, nil
+}
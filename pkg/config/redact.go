@@ -0,0 +1,58 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// redacted replaces the secrets which cannot be partially redacted
+const redacted = "[REDACTED]"
+
+// Redacted returns a copy of the configuration without its secrets, which is safe to log or serve
+func (c AppConfig) Redacted() AppConfig {
+	c.PG.URI = redactURI(c.PG.URI)
+
+	// The headers of the exporter carry the API keys of the vendors
+	headers := make(map[string]string, len(c.Otel.ExporterHeaders))
+	for k := range c.Otel.ExporterHeaders {
+		headers[k] = redacted
+	}
+	c.Otel.ExporterHeaders = headers
+
+	return c
+}
+
+// redactedParam replaces the secrets of the query of an URI, as url.URL.Redacted replaces the password
+const redactedParam = "xxxxx"
+
+// redactURI redacts the password of the URI and the secrets of its query, the URIs which cannot be parsed
+// are redacted entirely since the password cannot be told apart, e.g. a key/value connection string
+func redactURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" {
+		return redacted
+	}
+
+	q := u.Query()
+	secret := false
+	for k := range q {
+		if isSecretParam(k) {
+			q.Set(k, redactedParam)
+			secret = true
+		}
+	}
+	if secret {
+		u.RawQuery = q.Encode()
+	}
+
+	return u.Redacted()
+}
+
+// isSecretParam reports whether the query parameter carries a secret, e.g. the password of the client
+// certificate key of postgres
+func isSecretParam(key string) bool {
+	key = strings.ToLower(key)
+
+	return key == "password" || key == "sslpassword" ||
+		strings.Contains(key, "secret") || strings.Contains(key, "token")
+}
@@ -2,14 +2,59 @@ package di
 
 import (
 	"context"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/waiter"
 )
 
 // Get the dependency from the dependency container using the key
 func Get(ctx context.Context, key string) any {
+	return fromCtx(ctx).Get(key)
+}
+
+// TryGet the dependency from the dependency container using the key, it returns an error instead
+// of panicking when the context does not carry a container or the dependency cannot be resolved
+func TryGet(ctx context.Context, key string) (any, error) {
+	ctn, ok := ctx.Value(containerKey).(*container)
+	if !ok {
+		return nil, ErrNoContainer
+	}
+
+	return ctn.TryGet(key)
+}
+
+// IsRegistered reports whether ctx carries a container with a dependency registered with the key
+func IsRegistered(ctx context.Context, key string) bool {
+	ctn, ok := ctx.Value(containerKey).(*container)
+	if !ok {
+		return false
+	}
+
+	_, exists := ctn.deps[key]
+	return exists
+}
+
+// EndScope ends the scope created by Container.Scoped, it runs the disposers of the scoped
+// dependencies in reverse build order and returns the aggregated dispose errors
+func EndScope(ctx context.Context, cause error) error {
+	return fromCtx(ctx).Dispose(ctx, cause)
+}
+
+// DisposeOnCleanup disposes the singletons of the container once the waiter stopped the workers, so
+// that the disposers run after the modules and the jobs using the singletons are stopped, and before
+// the telemetry is flushed and the stores are closed
+func DisposeOnCleanup(w waiter.Waiter, c Container, log logger.Logger) {
+	w.Cleanup(waiter.PhaseDispose, "dependencies", func(ctx context.Context) error {
+		log.Infof("dispose dependencies")
+		return c.Dispose(ctx, nil)
+	})
+}
+
+func fromCtx(ctx context.Context) *container {
 	ctn, ok := ctx.Value(containerKey).(*container)
 	if !ok {
-		panic("container does not exist on context")
+		panic(ErrNoContainer.Error())
 	}
 
-	return ctn.Get(key)
+	return ctn
 }
@@ -2,6 +2,7 @@ package di
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 )
@@ -11,8 +12,23 @@ type Scope int
 const (
 	Singleton Scope = iota + 1
 	Scoped
+	// Transient dependencies are built on every Get, they are disposed with the scope they are built in
+	Transient
 )
 
+func (s Scope) String() string {
+	switch s {
+	case Singleton:
+		return "singleton"
+	case Scoped:
+		return "scoped"
+	case Transient:
+		return "transient"
+	default:
+		return fmt.Sprintf("Scope(%d)", int(s))
+	}
+}
+
 type contextKey int
 
 const containerKey contextKey = 1
@@ -26,53 +42,156 @@ const containerKey contextKey = 1
 //	}
 type DepFactoryFunc func(c Container) (any, error)
 
+// DisposeFunc representing for dependency dispose function, cause is the error the scope
+// has been ended with, it is nil when the scope ended successfully
+//
+//	func(ctx context.Context, v any, cause error) error {
+//		if cause != nil {
+//			return v.(*sql.Tx).Rollback()
+//		}
+//		return v.(*sql.Tx).Commit()
+//	}
+type DisposeFunc func(ctx context.Context, v any, cause error) error
+
+// DecoratorFunc representing for dependency decorator function, it returns the value wrapping v
+//
+//	func(c Container, v any) (any, error) {
+//		return tracing.NewUserRepository(v.(ports.UserRepository)), nil
+//	}
+type DecoratorFunc func(c Container, v any) (any, error)
+
 type tempValue = chan struct{}
 
 type Container interface {
-	AddSingleton(key string, fn DepFactoryFunc)
-	AddScoped(key string, fn DepFactoryFunc)
+	AddSingleton(key string, fn DepFactoryFunc, opts ...DepOption)
+	AddScoped(key string, fn DepFactoryFunc, opts ...DepOption)
+	AddTransient(key string, fn DepFactoryFunc, opts ...DepOption)
 	Scoped(ctx context.Context) context.Context
+
+	// Get gets the dependency, it panics with an *Error when the dependency cannot be resolved
 	Get(key string) any
+
+	// TryGet gets the dependency, it returns an *Error when the dependency cannot be resolved
+	TryGet(key string) (any, error)
+
+	// Decorate wraps the dependency registered with the key, or every member when the key is a group,
+	// the decorators are applied in the order they are added, whether they are added before or after
+	// the dependency is registered
+	Decorate(key string, fn DecoratorFunc)
+
+	// Contribute adds a dependency to the group, a group holds several implementations of a contract
+	// contributed e.g. by each module
+	Contribute(group string, scope Scope, fn DepFactoryFunc, opts ...DepOption)
+
+	// GetAll gets the dependencies contributed to the group in the contribution order
+	GetAll(group string) []any
+
+	// GetTagged gets the dependencies registered with the tag in the registration order
+	GetTagged(tag string) []any
+
+	// Dispose runs the disposers of the dependencies built by the container in reverse build order.
+	// Disposing the root container disposes the singletons, disposing a scoped container disposes
+	// the scoped dependencies. The container cannot be used after disposing.
+	Dispose(ctx context.Context, cause error) error
+
+	// Validate checks the declared dependencies of every registration, it reports the missing
+	// dependencies, the cycles and the singletons depending on scoped dependencies
+	Validate() error
+
+	// Graph returns the dependency graph built from the declared dependencies
+	Graph() Graph
 }
 
 type depInfo struct {
-	key     string
-	scope   Scope
-	factory DepFactoryFunc
+	key       string
+	scope     Scope
+	factory   DepFactoryFunc
+	disposer  DisposeFunc
+	dependsOn []string
+	tags      []string
+	group     string
+}
+
+// values holds the dependencies built by a container, it is shared between the container
+// and the builders created from it
+type values struct {
+	mu       sync.Mutex
+	m        map[string]any
+	built    []builtValue // in build order
+	disposed bool
+}
+
+type builtValue struct {
+	info depInfo
+	v    any
 }
 
 var _ Container = (*container)(nil)
 
 type container struct {
-	parent  *container
-	deps    map[string]depInfo
-	vals    map[string]any
-	tracked tracked
-	mu      sync.Mutex
+	parent     *container
+	deps       map[string]depInfo
+	groups     map[string][]string // group to the keys of its members
+	tags       map[string][]string // tag to the keys of the tagged dependencies
+	decorators map[string][]DecoratorFunc
+	vals       *values
+	tracked    tracked
 }
 
 // New initialize an dependency injection container
 func New() Container {
 	return &container{
-		deps: make(map[string]depInfo),
-		vals: make(map[string]any),
+		deps:       make(map[string]depInfo),
+		groups:     make(map[string][]string),
+		tags:       make(map[string][]string),
+		decorators: make(map[string][]DecoratorFunc),
+		vals:       newValues(),
 	}
 }
 
-func (c *container) AddSingleton(key string, fn DepFactoryFunc) {
-	c.deps[key] = depInfo{
-		key:     key,
-		scope:   Singleton,
-		factory: fn,
+func newValues() *values {
+	return &values{
+		m: make(map[string]any),
 	}
 }
 
-func (c *container) AddScoped(key string, fn DepFactoryFunc) {
-	c.deps[key] = depInfo{
+func (c *container) AddSingleton(key string, fn DepFactoryFunc, opts ...DepOption) {
+	c.add(key, Singleton, fn, opts)
+}
+
+func (c *container) AddScoped(key string, fn DepFactoryFunc, opts ...DepOption) {
+	c.add(key, Scoped, fn, opts)
+}
+
+func (c *container) AddTransient(key string, fn DepFactoryFunc, opts ...DepOption) {
+	c.add(key, Transient, fn, opts)
+}
+
+func (c *container) Contribute(group string, scope Scope, fn DepFactoryFunc, opts ...DepOption) {
+	key := fmt.Sprintf("%s[%d]", group, len(c.groups[group]))
+	c.groups[group] = append(c.groups[group], key)
+
+	c.add(key, scope, fn, append(opts, func(info *depInfo) {
+		info.group = group
+	}))
+}
+
+func (c *container) add(key string, scope Scope, fn DepFactoryFunc, opts []DepOption) {
+	info := depInfo{
 		key:     key,
-		scope:   Scoped,
+		scope:   scope,
 		factory: fn,
 	}
+
+	for _, opt := range opts {
+		opt(&info)
+	}
+
+	for _, tag := range info.tags {
+		c.tags[tag] = append(c.tags[tag], key)
+	}
+
+	c.deps[key] = info
 }
 
 func (c *container) Scoped(ctx context.Context) context.Context {
@@ -80,46 +199,111 @@ func (c *container) Scoped(ctx context.Context) context.Context {
 }
 
 func (c *container) Get(key string) any {
+	v, err := c.TryGet(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+func (c *container) TryGet(key string) (any, error) {
 	info, exists := c.deps[key]
 	if !exists {
-		panic(fmt.Sprintf("there is no dependency registered with `%s`", key))
+		return nil, c.newError(key, ErrNotRegistered)
 	}
 
 	// catch cases of: building Foo needs Bar and building Bar needs Foo :boom:
 	if _, exists := c.tracked[info.key]; exists {
-		panic(fmt.Sprintf("cyclic dependencies encountered while building `%s`, tracked: %s", info.key, c.tracked))
+		return nil, c.newError(key, ErrCyclic)
 	}
 
-	if info.scope == Singleton {
-		return c.getFromParent(info)
+	switch info.scope {
+	case Singleton:
+		// build the singleton in the root container, keeping the build chain of the requester
+		return c.root().withTracked(c.tracked).get(info)
+	case Transient:
+		return c.buildTransient(info)
+	default:
+		return c.get(info)
 	}
+}
 
-	return c.get(info)
+func (c *container) GetAll(group string) []any {
+	return c.getKeys(c.groups[group])
+}
+
+func (c *container) GetTagged(tag string) []any {
+	return c.getKeys(c.tags[tag])
+}
+
+func (c *container) getKeys(keys []string) []any {
+	rs := make([]any, len(keys))
+	for idx, key := range keys {
+		rs[idx] = c.Get(key)
+	}
+
+	return rs
+}
+
+func (c *container) Decorate(key string, fn DecoratorFunc) {
+	c.decorators[key] = append(c.decorators[key], fn)
 }
 
-func (c *container) getFromParent(info depInfo) any {
+func (c *container) Dispose(ctx context.Context, cause error) error {
+	c.vals.mu.Lock()
+	if c.vals.disposed {
+		c.vals.mu.Unlock()
+		return nil
+	}
+	c.vals.disposed = true
+	built := c.vals.built
+	c.vals.built, c.vals.m = nil, make(map[string]any)
+	c.vals.mu.Unlock()
+
+	var errs []error
+	for i := len(built) - 1; i >= 0; i-- {
+		info := built[i].info
+		if info.disposer == nil {
+			continue
+		}
+
+		if err := info.disposer(ctx, built[i].v, cause); err != nil {
+			errs = append(errs, fmt.Errorf("error disposing dependency `%s`: %w", info.key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *container) root() *container {
 	if c.parent != nil {
-		return c.parent.getFromParent(info)
+		return c.parent.root()
 	}
 
-	return c.get(info)
+	return c
 }
 
-func (c *container) get(info depInfo) any {
-	c.mu.Lock()
+func (c *container) get(info depInfo) (any, error) {
+	c.vals.mu.Lock()
+
+	if c.vals.disposed {
+		c.vals.mu.Unlock()
+		return nil, c.newError(info.key, ErrDisposed)
+	}
 
-	v, exists := c.vals[info.key]
+	v, exists := c.vals.m[info.key]
 	if !exists {
 		tv := make(tempValue)
-		c.vals[info.key] = tv
-		c.mu.Unlock()
+		c.vals.m[info.key] = tv
+		c.vals.mu.Unlock()
 		return c.build(info, tv)
 	}
 
-	c.mu.Unlock()
+	c.vals.mu.Unlock()
 	tv, isTemp := v.(tempValue)
 	if !isTemp {
-		return v
+		return v, nil
 	}
 
 	<-tv
@@ -127,38 +311,107 @@ func (c *container) get(info depInfo) any {
 	return c.get(info)
 }
 
-func (c *container) build(info depInfo, tv tempValue) any {
-	v, err := info.factory(c.builder(info))
+func (c *container) build(info depInfo, tv tempValue) (any, error) {
+	v, err := c.create(info)
 
-	c.mu.Lock()
+	c.vals.mu.Lock()
 
 	if err != nil {
-		delete(c.vals, info.key)
-		c.mu.Unlock()
+		delete(c.vals.m, info.key)
+		c.vals.mu.Unlock()
 		close(tv)
-		panic(fmt.Sprintf("error building dependency `%s`: %s", info.key, err))
+		return nil, err
 	}
 
-	c.vals[info.key] = v
-	c.mu.Unlock()
+	c.vals.m[info.key] = v
+	c.vals.built = append(c.vals.built, builtValue{info: info, v: v})
+	c.vals.mu.Unlock()
 	close(tv)
 
-	return v
+	return v, nil
+}
+
+func (c *container) buildTransient(info depInfo) (any, error) {
+	c.vals.mu.Lock()
+	disposed := c.vals.disposed
+	c.vals.mu.Unlock()
+
+	if disposed {
+		return nil, c.newError(info.key, ErrDisposed)
+	}
+
+	v, err := c.create(info)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the values to be disposed are kept
+	if info.disposer != nil {
+		c.vals.mu.Lock()
+		c.vals.built = append(c.vals.built, builtValue{info: info, v: v})
+		c.vals.mu.Unlock()
+	}
+
+	return v, nil
+}
+
+// create calls the factory then the decorators of the dependency, the decorators of the group
+// are applied to each member. Panics of the factory and the decorators, e.g. a failed Get of a
+// nested dependency, are returned as errors.
+func (c *container) create(info depInfo) (v any, err error) {
+	b := c.builder(info)
+
+	defer func() {
+		if p := recover(); p != nil {
+			pErr, ok := p.(error)
+			if !ok {
+				pErr = fmt.Errorf("panic: %v", p)
+			}
+			v, err = nil, c.newError(info.key, pErr)
+		}
+	}()
+
+	if v, err = info.factory(b); err != nil {
+		return nil, c.newError(info.key, err)
+	}
+
+	decorators := c.decorators[info.key]
+	if info.group != "" {
+		decorators = append(append([]DecoratorFunc{}, decorators...), c.decorators[info.group]...)
+	}
+
+	for _, decorate := range decorators {
+		if v, err = decorate(b, v); err != nil {
+			return nil, c.newError(info.key, err)
+		}
+	}
+
+	return v, nil
 }
 
 func (c *container) scoped() *container {
 	return &container{
-		parent: c,
-		deps:   c.deps,
-		vals:   make(map[string]any),
+		parent:     c,
+		deps:       c.deps,
+		groups:     c.groups,
+		tags:       c.tags,
+		decorators: c.decorators,
+		vals:       newValues(),
 	}
 }
 
-func (c *container) builder(info depInfo) *container {
+func (c *container) withTracked(tracked tracked) *container {
 	return &container{
-		parent:  c.parent,
-		deps:    c.deps,
-		vals:    c.vals,
-		tracked: c.tracked.add(info),
+		parent:     c.parent,
+		deps:       c.deps,
+		groups:     c.groups,
+		tags:       c.tags,
+		decorators: c.decorators,
+		vals:       c.vals,
+		tracked:    tracked,
 	}
 }
+
+func (c *container) builder(info depInfo) *container {
+	return c.withTracked(c.tracked.add(info))
+}
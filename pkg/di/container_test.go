@@ -0,0 +1,132 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/waiter"
+)
+
+func TestEndScope(t *testing.T) {
+	errFailed := errors.New("failed")
+	errClose := errors.New("close error")
+
+	tcs := map[string]struct {
+		cause       error
+		closeErr    error
+		expDisposed []string
+		expErr      string
+	}{
+		"ended successfully": {
+			expDisposed: []string{"handler:<nil>", "tx:<nil>"},
+		},
+		"ended with cause": {
+			cause:       errFailed,
+			expDisposed: []string{"handler:failed", "tx:failed"},
+		},
+		"dispose error": {
+			closeErr:    errClose,
+			expDisposed: []string{"handler:<nil>", "tx:<nil>"},
+			expErr:      "error disposing dependency `tx`: close error",
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			var disposed []string
+			disposer := func(ctx context.Context, v string, cause error) error {
+				disposed = append(disposed, v+":"+errString(cause))
+				if v == "tx" {
+					return tc.closeErr
+				}
+				return nil
+			}
+
+			c := New()
+			c.AddSingleton("db", func(c Container) (any, error) {
+				return "db", nil
+			}, WithDisposer(disposer))
+			c.AddScoped("tx", func(c Container) (any, error) {
+				c.Get("db")
+				return "tx", nil
+			}, WithDisposer(disposer))
+			c.AddScoped("handler", func(c Container) (any, error) {
+				c.Get("tx")
+				return "handler", nil
+			}, WithDisposer(disposer))
+
+			ctx := c.Scoped(context.Background())
+			Get(ctx, "handler")
+
+			// When
+			err := EndScope(ctx, tc.cause)
+
+			// Then
+			if tc.expErr != "" {
+				require.EqualError(t, err, tc.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expDisposed, disposed)
+
+			// Singletons are disposed with the root container only
+			require.NoError(t, c.Dispose(context.Background(), nil))
+			require.Equal(t, append(tc.expDisposed, "db:<nil>"), disposed)
+		})
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+
+	return err.Error()
+}
+
+func TestDisposeOnCleanup(t *testing.T) {
+	// Given
+	w := waiter.New(waiter.ShutdownTimeout(time.Second))
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, name)
+	}
+
+	c := New()
+	c.AddSingleton("repository", func(c Container) (any, error) {
+		return "repository", nil
+	}, WithDisposer(func(ctx context.Context, v any, cause error) error {
+		record("dispose " + v.(string))
+		return nil
+	}))
+	c.Get("repository")
+
+	DisposeOnCleanup(w, c, logger.NewNoop())
+
+	// The modules use the singletons until their shutdown returns
+	w.Cleanup(waiter.PhaseStopWorkers, "modules", func(ctx context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		record("modules shutdown")
+		return nil
+	})
+
+	// When
+	w.CancelFunc()()
+	err := w.Wait()
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []string{"modules shutdown", "dispose repository"}, calls)
+}
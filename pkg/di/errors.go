@@ -0,0 +1,46 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrNotRegistered = errors.New("dependency is not registered")
+	ErrCyclic        = errors.New("cyclic dependencies")
+	ErrDisposed      = errors.New("container is disposed")
+	ErrWrongType     = errors.New("dependency is of wrong type")
+	ErrNoContainer   = errors.New("container does not exist on context")
+)
+
+// Error representing an error resolving a dependency, Chain is the build chain from the first
+// dependency requested to the dependency which failed
+type Error struct {
+	Key   string
+	Chain []string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("error resolving dependency `%s` (chain: %s): %s", e.Key, strings.Join(e.Chain, " -> "), e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError creates the error resolving the key, the errors of the nested dependencies are kept
+// as they are since their chain already contains the key
+func (c *container) newError(key string, err error) error {
+	var resolveErr *Error
+	if errors.As(err, &resolveErr) {
+		return err
+	}
+
+	return &Error{
+		Key:   key,
+		Chain: append(c.tracked.ordered(), key),
+		Err:   err,
+	}
+}
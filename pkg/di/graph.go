@@ -0,0 +1,99 @@
+package di
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Graph representing the dependency graph of a container, it can be encoded to JSON or written as DOT
+//
+//	dot -Tsvg -o deps.svg deps.dot
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+}
+
+// Node representing a registered dependency and the dependencies it declares
+type Node struct {
+	Key       string   `json:"key"`
+	Scope     string   `json:"scope"`
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// groupScope is the scope of the nodes standing for the groups, their members are their dependencies
+const groupScope = "group"
+
+func (c *container) Graph() Graph {
+	keys := c.sortedKeys()
+
+	g := Graph{Nodes: make([]Node, 0, len(keys)+len(c.groups))}
+	for _, key := range keys {
+		info := c.deps[key]
+		g.Nodes = append(g.Nodes, Node{
+			Key:       key,
+			Scope:     info.scope.String(),
+			DependsOn: info.dependsOn,
+		})
+	}
+
+	groups := make([]string, 0, len(c.groups))
+	for group := range c.groups {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		g.Nodes = append(g.Nodes, Node{
+			Key:       group,
+			Scope:     groupScope,
+			DependsOn: c.groups[group],
+		})
+	}
+
+	return g
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language, singletons are drawn as boxes, scoped
+// dependencies as ellipses, transient dependencies as diamonds, groups as folders and missing
+// dependencies in red
+func (g Graph) WriteDOT(w io.Writer) error {
+	registered := make(map[string]bool, len(g.Nodes))
+	for _, n := range g.Nodes {
+		registered[n.Key] = true
+	}
+
+	if _, err := fmt.Fprintln(w, "digraph dependencies {"); err != nil {
+		return err
+	}
+
+	for _, n := range g.Nodes {
+		shape := "ellipse"
+		switch n.Scope {
+		case Singleton.String():
+			shape = "box"
+		case Transient.String():
+			shape = "diamond"
+		case groupScope:
+			shape = "folder"
+		}
+
+		if _, err := fmt.Fprintf(w, "\t%q [shape=%s, tooltip=%q];\n", n.Key, shape, n.Scope); err != nil {
+			return err
+		}
+
+		for _, dep := range n.DependsOn {
+			if !registered[dep] {
+				if _, err := fmt.Fprintf(w, "\t%q [color=red, fontcolor=red];\n", dep); err != nil {
+					return err
+				}
+			}
+
+			if _, err := fmt.Fprintf(w, "\t%q -> %q;\n", n.Key, dep); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
@@ -0,0 +1,191 @@
+package di
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Key is a typed dependency key, the type parameter T is the type of the registered dependency.
+// Declare keys once as package variables and share them between registration and resolution:
+//
+//	var UserRepositoryKey = di.NewKey[ports.UserRepository]("users.repository")
+type Key[T any] struct {
+	name string
+}
+
+// NewKey creates a typed dependency key with the given name
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// String returns the name of the key, which is the key used by the underlying Container
+func (k Key[T]) String() string {
+	return k.name
+}
+
+// Factory representing for typed dependency factory function
+//
+//	func(c Container) (ports.UserRepository, error) {
+//		return repository.New(di.Resolve(c, DBKey)), nil
+//	}
+type Factory[T any] func(c Container) (T, error)
+
+// Register adds a typed dependency to the container with the given scope
+func Register[T any](c Container, key Key[T], scope Scope, fn Factory[T], opts ...DepOption) {
+	factory := func(c Container) (any, error) {
+		return fn(c)
+	}
+
+	switch scope {
+	case Singleton:
+		c.AddSingleton(key.name, factory, opts...)
+	case Scoped:
+		c.AddScoped(key.name, factory, opts...)
+	case Transient:
+		c.AddTransient(key.name, factory, opts...)
+	default:
+		panic(fmt.Sprintf("unknown scope `%d` registering `%s`", scope, key.name))
+	}
+}
+
+// AddSingleton adds a typed singleton dependency to the container
+func AddSingleton[T any](c Container, key Key[T], fn Factory[T], opts ...DepOption) {
+	Register(c, key, Singleton, fn, opts...)
+}
+
+// AddScoped adds a typed scoped dependency to the container
+func AddScoped[T any](c Container, key Key[T], fn Factory[T], opts ...DepOption) {
+	Register(c, key, Scoped, fn, opts...)
+}
+
+// AddTransient adds a typed transient dependency to the container
+func AddTransient[T any](c Container, key Key[T], fn Factory[T], opts ...DepOption) {
+	Register(c, key, Transient, fn, opts...)
+}
+
+// Contribute adds a typed dependency to the group identified by the key
+//
+//	var HealthCheckersKey = di.NewKey[[]health.Checker]("health.checkers")
+//
+//	di.Contribute(c, HealthCheckersKey, di.Singleton, func(c di.Container) (health.Checker, error) {
+//		return dbChecker{}, nil
+//	})
+func Contribute[T any](c Container, key Key[[]T], scope Scope, fn Factory[T], opts ...DepOption) {
+	c.Contribute(key.name, scope, func(c Container) (any, error) {
+		return fn(c)
+	}, opts...)
+}
+
+// Resolve the dependency registered with the key from the container
+func Resolve[T any](c Container, key Key[T]) T {
+	return cast[T](key.name, c.Get(key.name))
+}
+
+// ResolveCtx resolves the dependency registered with the key from the container on context
+func ResolveCtx[T any](ctx context.Context, key Key[T]) T {
+	return cast[T](key.name, Get(ctx, key.name))
+}
+
+// ResolveE resolves the dependency registered with the key from the container, it returns an
+// error instead of panicking when the dependency cannot be resolved
+func ResolveE[T any](c Container, key Key[T]) (T, error) {
+	v, err := c.TryGet(key.name)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return castE[T](key.name, v)
+}
+
+// ResolveCtxE resolves the dependency registered with the key from the container on context, it
+// returns an error instead of panicking when the dependency cannot be resolved
+func ResolveCtxE[T any](ctx context.Context, key Key[T]) (T, error) {
+	v, err := TryGet(ctx, key.name)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return castE[T](key.name, v)
+}
+
+// Decorate wraps the dependency registered with the key, e.g. with tracing or caching, without
+// changing its factory
+//
+//	di.Decorate(c, UserRepositoryKey, func(c di.Container, repo ports.UserRepository) (ports.UserRepository, error) {
+//		return cache.NewUserRepository(repo), nil
+//	})
+func Decorate[T any](c Container, key Key[T], fn func(c Container, v T) (T, error)) {
+	c.Decorate(key.name, func(c Container, v any) (any, error) {
+		tv, err := castE[T](key.name, v)
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(c, tv)
+	})
+}
+
+// DecorateAll wraps every member of the group identified by the key
+func DecorateAll[T any](c Container, key Key[[]T], fn func(c Container, v T) (T, error)) {
+	Decorate(c, NewKey[T](key.name), fn)
+}
+
+// ResolveAll resolves the dependencies contributed to the group identified by the key
+func ResolveAll[T any](c Container, key Key[[]T]) []T {
+	return castAll[T](key.name, c.GetAll(key.name))
+}
+
+// ResolveAllCtx resolves the dependencies contributed to the group identified by the key from
+// the container on context
+func ResolveAllCtx[T any](ctx context.Context, key Key[[]T]) []T {
+	return castAll[T](key.name, fromCtx(ctx).GetAll(key.name))
+}
+
+// ResolveTagged resolves the dependencies registered with the tag which are of type T, the tagged
+// dependencies of other types are skipped
+func ResolveTagged[T any](c Container, tag string) []T {
+	var rs []T
+	for _, v := range c.GetTagged(tag) {
+		if tv, ok := v.(T); ok {
+			rs = append(rs, tv)
+		}
+	}
+
+	return rs
+}
+
+func castAll[T any](key string, vals []any) []T {
+	rs := make([]T, len(vals))
+	for idx, v := range vals {
+		rs[idx] = cast[T](key, v)
+	}
+
+	return rs
+}
+
+func cast[T any](key string, v any) T {
+	rs, err := castE[T](key, v)
+	if err != nil {
+		panic(err)
+	}
+
+	return rs
+}
+
+func castE[T any](key string, v any) (T, error) {
+	// A nil interface value cannot be asserted, return the zero value instead
+	if v == nil {
+		var zero T
+		return zero, nil
+	}
+
+	rs, ok := v.(T)
+	if !ok {
+		return rs, fmt.Errorf("%w: dependency `%s` is of type %T, not %s", ErrWrongType, key, v, reflect.TypeOf((*T)(nil)).Elem())
+	}
+
+	return rs, nil
+}
@@ -0,0 +1,272 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type greeter interface {
+	Greet() string
+}
+
+type englishGreeter struct {
+	name string
+}
+
+func (g englishGreeter) Greet() string {
+	return "hello " + g.name
+}
+
+func TestResolve(t *testing.T) {
+	nameKey := NewKey[string]("name")
+	greeterKey := NewKey[greeter]("greeter")
+
+	tcs := map[string]struct {
+		scope    Scope
+		expSame  bool
+		expGreet string
+	}{
+		"singleton": {
+			scope:    Singleton,
+			expSame:  true,
+			expGreet: "hello world",
+		},
+		"scoped": {
+			scope:    Scoped,
+			expSame:  false,
+			expGreet: "hello world",
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			builds := 0
+			c := New()
+			AddSingleton(c, nameKey, func(c Container) (string, error) {
+				return "world", nil
+			})
+			Register(c, greeterKey, tc.scope, func(c Container) (greeter, error) {
+				builds++
+				return englishGreeter{name: Resolve(c, nameKey)}, nil
+			})
+
+			// When
+			first := ResolveCtx(c.Scoped(context.Background()), greeterKey)
+			second := ResolveCtx(c.Scoped(context.Background()), greeterKey)
+
+			// Then
+			require.Equal(t, tc.expGreet, first.Greet())
+			require.Equal(t, tc.expGreet, second.Greet())
+			if tc.expSame {
+				require.Equal(t, 1, builds)
+			} else {
+				require.Equal(t, 2, builds)
+			}
+		})
+	}
+}
+
+func TestResolve_Panics(t *testing.T) {
+	tcs := map[string]struct {
+		setup  func(c Container)
+		expMsg string
+	}{
+		"not registered": {
+			setup:  func(c Container) {},
+			expMsg: "error resolving dependency `greeter` (chain: greeter): dependency is not registered",
+		},
+		"wrong type": {
+			setup: func(c Container) {
+				c.AddSingleton("greeter", func(c Container) (any, error) {
+					return "hello", nil
+				})
+			},
+			expMsg: "dependency is of wrong type: dependency `greeter` is of type string, not di.greeter",
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			c := New()
+			tc.setup(c)
+
+			// When & Then
+			require.PanicsWithError(t, tc.expMsg, func() {
+				Resolve(c, NewKey[greeter]("greeter"))
+			})
+		})
+	}
+}
+
+func TestResolve_NilInterface(t *testing.T) {
+	// Given
+	c := New()
+	key := NewKey[greeter]("greeter")
+	AddSingleton(c, key, func(c Container) (greeter, error) {
+		return nil, nil
+	})
+
+	// When
+	g := Resolve(c, key)
+
+	// Then
+	require.Nil(t, g)
+}
+
+func TestResolveAll(t *testing.T) {
+	// Given
+	greetersKey := NewKey[[]greeter]("greeters")
+	c := New()
+	for _, name := range []string{"alice", "bob"} {
+		name := name
+		Contribute(c, greetersKey, Singleton, func(c Container) (greeter, error) {
+			return englishGreeter{name: name}, nil
+		}, WithTags("english"))
+	}
+	c.AddSingleton("farewell", func(c Container) (any, error) {
+		return "bye", nil
+	}, WithTags("english"))
+
+	// When
+	all := ResolveAll(c, greetersKey)
+	tagged := ResolveTagged[greeter](c, "english")
+
+	// Then
+	require.Equal(t, []greeter{englishGreeter{name: "alice"}, englishGreeter{name: "bob"}}, all)
+	require.Equal(t, all, tagged)
+	require.Len(t, c.GetTagged("english"), 3)
+}
+
+func TestAddTransient(t *testing.T) {
+	// Given
+	key := NewKey[*int]("counter")
+	builds, disposed := 0, 0
+	c := New()
+	AddTransient(c, key, func(c Container) (*int, error) {
+		builds++
+		v := builds
+		return &v, nil
+	}, WithDisposer(func(ctx context.Context, v *int, cause error) error {
+		disposed++
+		return nil
+	}))
+	ctx := c.Scoped(context.Background())
+
+	// When
+	first, second := ResolveCtx(ctx, key), ResolveCtx(ctx, key)
+	err := EndScope(ctx, nil)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, 1, *first)
+	require.Equal(t, 2, *second)
+	require.Equal(t, 2, disposed)
+}
+
+func TestResolveE(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tcs := map[string]struct {
+		setup  func(c Container)
+		expErr error
+		expMsg string
+	}{
+		"not registered": {
+			setup:  func(c Container) {},
+			expErr: ErrNotRegistered,
+			expMsg: "error resolving dependency `greeter` (chain: greeter): dependency is not registered",
+		},
+		"nested dependency failed": {
+			setup: func(c Container) {
+				c.AddSingleton("name", func(c Container) (any, error) {
+					return nil, errFailed
+				})
+				c.AddScoped("greeter", func(c Container) (any, error) {
+					return englishGreeter{name: c.Get("name").(string)}, nil
+				})
+			},
+			expErr: errFailed,
+			expMsg: "error resolving dependency `name` (chain: greeter -> name): failed",
+		},
+		"cyclic dependencies": {
+			setup: func(c Container) {
+				c.AddSingleton("name", func(c Container) (any, error) {
+					return c.Get("greeter"), nil
+				})
+				c.AddScoped("greeter", func(c Container) (any, error) {
+					return c.Get("name"), nil
+				})
+			},
+			expErr: ErrCyclic,
+			expMsg: "error resolving dependency `greeter` (chain: greeter -> name -> greeter): cyclic dependencies",
+		},
+		"wrong type": {
+			setup: func(c Container) {
+				c.AddSingleton("greeter", func(c Container) (any, error) {
+					return "hello", nil
+				})
+			},
+			expErr: ErrWrongType,
+			expMsg: "dependency is of wrong type: dependency `greeter` is of type string, not di.greeter",
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			c := New()
+			tc.setup(c)
+
+			// When
+			_, err := ResolveCtxE(c.Scoped(context.Background()), NewKey[greeter]("greeter"))
+
+			// Then
+			require.ErrorIs(t, err, tc.expErr)
+			require.EqualError(t, err, tc.expMsg)
+		})
+	}
+}
+
+type politeGreeter struct {
+	greeter
+}
+
+func (g politeGreeter) Greet() string {
+	return g.greeter.Greet() + ", nice to meet you"
+}
+
+func TestDecorate(t *testing.T) {
+	// Given
+	greeterKey := NewKey[greeter]("greeter")
+	greetersKey := NewKey[[]greeter]("greeters")
+	polite := func(c Container, g greeter) (greeter, error) {
+		return politeGreeter{greeter: g}, nil
+	}
+
+	c := New()
+	Decorate(c, greeterKey, polite)
+	AddSingleton(c, greeterKey, func(c Container) (greeter, error) {
+		return englishGreeter{name: "alice"}, nil
+	})
+	Contribute(c, greetersKey, Transient, func(c Container) (greeter, error) {
+		return englishGreeter{name: "bob"}, nil
+	})
+	DecorateAll(c, greetersKey, polite)
+
+	// When
+	g := Resolve(c, greeterKey)
+	all := ResolveAll(c, greetersKey)
+
+	// Then
+	require.Equal(t, "hello alice, nice to meet you", g.Greet())
+	require.Len(t, all, 1)
+	require.Equal(t, "hello bob, nice to meet you", all[0].Greet())
+}
@@ -0,0 +1,32 @@
+package di
+
+import (
+	"context"
+)
+
+type DepOption func(info *depInfo)
+
+// WithDisposer sets the function disposing the dependency when its scope ends, e.g. closing
+// a connection, committing or rolling back a transaction depending on the cause
+func WithDisposer[T any](fn func(ctx context.Context, v T, cause error) error) DepOption {
+	return func(info *depInfo) {
+		info.disposer = func(ctx context.Context, v any, cause error) error {
+			return fn(ctx, cast[T](info.key, v), cause)
+		}
+	}
+}
+
+// DependsOn declares the keys of the dependencies the factory gets from the container, they are
+// used to validate the container and to export the dependency graph
+func DependsOn(keys ...string) DepOption {
+	return func(info *depInfo) {
+		info.dependsOn = append(info.dependsOn, keys...)
+	}
+}
+
+// WithTags tags the dependency, the dependencies sharing a tag are resolved together with GetTagged
+func WithTags(tags ...string) DepOption {
+	return func(info *depInfo) {
+		info.tags = append(info.tags, tags...)
+	}
+}
@@ -0,0 +1,120 @@
+package di
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidationError reports every problem found while validating a container
+type ValidationError struct {
+	Problems []string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("dependency container is invalid:\n  - %s", strings.Join(e.Problems, "\n  - "))
+}
+
+func (c *container) Validate() error {
+	var problems []string
+
+	keys := c.sortedKeys()
+	for _, key := range keys {
+		info := c.deps[key]
+		for _, depKey := range info.dependsOn {
+			members, exists := c.lookup(depKey)
+			if !exists {
+				problems = append(problems, fmt.Sprintf("`%s` depends on `%s` which is not registered", key, depKey))
+				continue
+			}
+
+			// the scoped dependency would be captured by the singleton and shared between scopes
+			for _, member := range members {
+				if info.scope == Singleton && c.deps[member].scope == Scoped {
+					problems = append(problems, fmt.Sprintf("singleton `%s` depends on scoped `%s`", key, member))
+				}
+			}
+		}
+	}
+
+	for _, cycle := range c.cycles(keys) {
+		problems = append(problems, fmt.Sprintf("cyclic dependencies: %s", strings.Join(cycle, " -> ")))
+	}
+
+	if len(problems) > 0 {
+		return ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// cycles finds the cycles of the declared dependencies using a depth-first search
+func (c *container) cycles(keys []string) [][]string {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	var (
+		rs    [][]string
+		state = make(map[string]int, len(keys))
+		path  []string
+		visit func(key string)
+	)
+
+	visit = func(key string) {
+		state[key] = visiting
+		path = append(path, key)
+
+		for _, depKey := range c.deps[key].dependsOn {
+			members, _ := c.lookup(depKey)
+			for _, member := range members {
+				switch state[member] {
+				case visiting:
+					// the dependency is on the current path, collect the path from it
+					for i := range path {
+						if path[i] == member {
+							cycle := append(append([]string{}, path[i:]...), member)
+							rs = append(rs, cycle)
+							break
+						}
+					}
+				case 0:
+					visit(member)
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[key] = visited
+	}
+
+	for _, key := range keys {
+		if state[key] == 0 {
+			visit(key)
+		}
+	}
+
+	return rs
+}
+
+// lookup returns the keys of the dependencies the declared key stands for, which are the members
+// of the group when the key is a group
+func (c *container) lookup(key string) ([]string, bool) {
+	if _, exists := c.deps[key]; exists {
+		return []string{key}, true
+	}
+
+	members, exists := c.groups[key]
+	return members, exists
+}
+
+func (c *container) sortedKeys() []string {
+	keys := make([]string, 0, len(c.deps))
+	for key := range c.deps {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,93 @@
+package di
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	factory := func(c Container) (any, error) {
+		return nil, nil
+	}
+
+	tcs := map[string]struct {
+		setup       func(c Container)
+		expProblems []string
+	}{
+		"valid": {
+			setup: func(c Container) {
+				c.AddSingleton("db", factory)
+				c.AddScoped("tx", factory, DependsOn("db"))
+				c.AddScoped("repository", factory, DependsOn("tx"))
+			},
+		},
+		"missing dependency": {
+			setup: func(c Container) {
+				c.AddScoped("repository", factory, DependsOn("tx"))
+			},
+			expProblems: []string{"`repository` depends on `tx` which is not registered"},
+		},
+		"singleton depends on scoped": {
+			setup: func(c Container) {
+				c.AddScoped("tx", factory)
+				c.AddSingleton("repository", factory, DependsOn("tx"))
+			},
+			expProblems: []string{"singleton `repository` depends on scoped `tx`"},
+		},
+		"cycle": {
+			setup: func(c Container) {
+				c.AddSingleton("a", factory, DependsOn("b"))
+				c.AddSingleton("b", factory, DependsOn("c"))
+				c.AddSingleton("c", factory, DependsOn("a"))
+				c.AddSingleton("d", factory, DependsOn("d"))
+			},
+			expProblems: []string{
+				"cyclic dependencies: a -> b -> c -> a",
+				"cyclic dependencies: d -> d",
+			},
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			c := New()
+			tc.setup(c)
+
+			// When
+			err := c.Validate()
+
+			// Then
+			if tc.expProblems == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, ValidationError{Problems: tc.expProblems}, err)
+		})
+	}
+}
+
+func TestGraph_WriteDOT(t *testing.T) {
+	// Given
+	c := New()
+	c.AddSingleton("db", nil)
+	c.AddScoped("tx", nil, DependsOn("db", "logger"))
+
+	// When
+	var buf bytes.Buffer
+	err := c.Graph().WriteDOT(&buf)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, `digraph dependencies {
+	"db" [shape=box, tooltip="singleton"];
+	"tx" [shape=ellipse, tooltip="scoped"];
+	"tx" -> "db";
+	"logger" [color=red, fontcolor=red];
+	"tx" -> "logger";
+}
+`, buf.String())
+}
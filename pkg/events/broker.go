@@ -0,0 +1,234 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/virsavik/alchemist-template/pkg/backoff"
+	"github.com/virsavik/alchemist-template/pkg/logger"
+)
+
+const (
+	scopeName = "github.com/virsavik/alchemist-template/pkg/events"
+	version   = "1.0.0"
+
+	defaultBufferSize  = 1024
+	defaultConcurrency = 1
+	defaultMaxAttempts = 3
+)
+
+var _ Bus = (*Broker)(nil)
+
+// Broker is an in-memory Bus delivering the events to the handlers of the same process, the events
+// are buffered until Run delivers them. A failing handler is retried with a backoff, the event is
+// dropped and logged once the attempts are exhausted.
+type Broker struct {
+	logger      logger.Logger
+	bufferSize  int
+	concurrency int
+	maxAttempts int
+	backoff     backoff.Exponential
+	tracer      trace.Tracer
+
+	mu         sync.RWMutex
+	handlers   map[string][]subscription
+	closed     bool
+	publishing sync.WaitGroup
+	queue      chan Envelope
+	closing    chan struct{}
+	drained    chan struct{}
+}
+
+type subscription struct {
+	name string
+	fn   Handler
+}
+
+// NewBroker initializes an in-memory broker
+func NewBroker(opts ...Option) *Broker {
+	b := &Broker{
+		logger:      logger.NewNoop(),
+		bufferSize:  defaultBufferSize,
+		concurrency: defaultConcurrency,
+		maxAttempts: defaultMaxAttempts,
+		handlers:    make(map[string][]subscription),
+		tracer: otel.GetTracerProvider().Tracer(scopeName,
+			trace.WithInstrumentationVersion(version),
+		),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.queue = make(chan Envelope, b.bufferSize)
+	b.closing = make(chan struct{})
+	b.drained = make(chan struct{})
+
+	return b
+}
+
+func (b *Broker) Subscribe(event string, name string, fn Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[event] = append(b.handlers[event], subscription{name: name, fn: fn})
+}
+
+func (b *Broker) Publish(ctx context.Context, events ...Envelope) error {
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrClosed
+	}
+	b.publishing.Add(1)
+	b.mu.RUnlock()
+
+	defer b.publishing.Done()
+
+	for _, e := range events {
+		_, span := b.tracer.Start(ctx, "events.publish "+e.Name,
+			trace.WithAttributes(eventAttributes(e)...),
+			trace.WithSpanKind(trace.SpanKindProducer),
+		)
+
+		// The handlers spans are linked to the publishing span
+		e.TraceContext = map[string]string{}
+		otel.GetTextMapPropagator().Inject(trace.ContextWithSpan(ctx, span), propagation.MapCarrier(e.TraceContext))
+
+		select {
+		case b.queue <- e:
+			span.End()
+		case <-ctx.Done():
+			span.RecordError(ctx.Err())
+			span.End()
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Run delivers the published events until the broker is closed, the buffered events are delivered
+// before returning. It keeps running once ctx is done, so that the events published while the servers
+// drain their in-flight requests are delivered.
+func (b *Broker) Run(ctx context.Context) error {
+	var workers sync.WaitGroup
+	for i := 0; i < b.concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+
+			// The delivery is not canceled with ctx, so that the buffered events are delivered on shutdown
+			for e := range b.queue {
+				b.deliver(context.Background(), e)
+			}
+		}()
+	}
+
+	<-b.closing
+
+	// The publishers blocked on a full buffer complete while the workers drain it
+	b.publishing.Wait()
+	close(b.queue)
+
+	workers.Wait()
+	close(b.drained)
+
+	return nil
+}
+
+// Close stops accepting new events and waits for Run to deliver the buffered ones, it returns the
+// error of ctx when ctx is done first
+func (b *Broker) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.closing)
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-b.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Broker) deliver(ctx context.Context, e Envelope) {
+	b.mu.RLock()
+	subs := b.handlers[e.Name]
+	b.mu.RUnlock()
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(e.TraceContext))
+
+	for _, sub := range subs {
+		b.handle(ctx, e, sub)
+	}
+}
+
+// handle delivers the event to the handler until it succeeds or the attempts are exhausted
+func (b *Broker) handle(ctx context.Context, e Envelope, sub subscription) {
+	ctx, span := b.tracer.Start(ctx, "events.handle "+e.Name,
+		trace.WithAttributes(append(eventAttributes(e), attribute.String("messaging.consumer.name", sub.name))...),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+	)
+	defer span.End()
+
+	log := b.logger.With(
+		logger.String("messaging.message.id", e.ID),
+		logger.String("messaging.destination.name", e.Name),
+		logger.String("messaging.consumer.name", sub.name),
+		logger.String("trace.id", span.SpanContext().TraceID().String()),
+	)
+	ctx = logger.SetInCtx(ctx, log)
+
+	var err error
+	for attempt := 1; attempt <= b.maxAttempts; attempt++ {
+		if err = b.call(ctx, e, sub); err == nil {
+			return
+		}
+
+		span.AddEvent("handle failed", trace.WithAttributes(
+			attribute.Int("messaging.attempt", attempt),
+			attribute.String("exception.message", err.Error()),
+		))
+
+		if attempt < b.maxAttempts {
+			log.Warnf("handle event failed, attempt %d/%d: %v", attempt, b.maxAttempts, err)
+			_ = b.backoff.Wait(ctx, attempt-1)
+		}
+	}
+
+	log.Errorf(err, "handle event failed after %d attempts, the event is dropped", b.maxAttempts)
+	span.RecordError(err, trace.WithStackTrace(true))
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// call calls the handler, recovering its panic as an error
+func (b *Broker) call(ctx context.Context, e Envelope, sub subscription) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return sub.fn(ctx, e)
+}
+
+func eventAttributes(e Envelope) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("in-memory"),
+		semconv.MessagingMessageID(e.ID),
+		semconv.MessagingDestinationName(e.Name),
+	}
+}
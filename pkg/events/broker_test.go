@@ -0,0 +1,161 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/virsavik/alchemist-template/pkg/backoff"
+)
+
+type userCreated struct {
+	Email string
+}
+
+var userCreatedTopic = NewTopic[userCreated]("users.user_created")
+
+func TestBroker_Deliver(t *testing.T) {
+	errHandle := errors.New("smtp unavailable")
+
+	tcs := map[string]struct {
+		fails       int
+		panics      bool
+		expAttempts int
+	}{
+		"delivered": {
+			expAttempts: 1,
+		},
+		"retried": {
+			fails:       2,
+			expAttempts: 3,
+		},
+		"dropped after the attempts": {
+			fails:       5,
+			expAttempts: 3,
+		},
+		"panic recovered": {
+			fails:       1,
+			panics:      true,
+			expAttempts: 2,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			b := NewBroker(Retry(3, backoff.Exponential{Initial: time.Millisecond}))
+
+			var mu sync.Mutex
+			var emails []string
+			attempts := 0
+			Subscribe(b, userCreatedTopic, "send welcome email", func(ctx context.Context, e Envelope, payload userCreated) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				attempts++
+				if attempts <= tc.fails {
+					if tc.panics {
+						panic("nil mailer")
+					}
+					return errHandle
+				}
+
+				emails = append(emails, payload.Email)
+				return nil
+			})
+
+			go func() { _ = b.Run(context.Background()) }()
+
+			// When
+			err := Publish(context.Background(), b, userCreatedTopic, userCreated{Email: "john@example.com"})
+			require.NoError(t, err)
+			require.NoError(t, b.Close(context.Background()))
+
+			// Then
+			require.Equal(t, tc.expAttempts, attempts)
+			if tc.fails < tc.expAttempts {
+				require.Equal(t, []string{"john@example.com"}, emails)
+			} else {
+				require.Empty(t, emails)
+			}
+		})
+	}
+}
+
+func TestBroker_Close(t *testing.T) {
+	// Given
+	b := NewBroker(BufferSize(10))
+
+	delivered := 0
+	b.Subscribe(userCreatedTopic.String(), "count", func(ctx context.Context, e Envelope) error {
+		delivered++
+		return nil
+	})
+
+	// The events are buffered before the broker runs
+	for i := 0; i < 5; i++ {
+		require.NoError(t, Publish(context.Background(), b, userCreatedTopic, userCreated{}))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan error)
+	go func() { done <- b.Run(ctx) }()
+
+	// When
+	err := b.Close(context.Background())
+
+	// Then
+	require.NoError(t, err)
+	require.NoError(t, <-done)
+	require.Equal(t, 5, delivered)
+	require.ErrorIs(t, Publish(context.Background(), b, userCreatedTopic, userCreated{}), ErrClosed)
+}
+
+func TestSubscribe_WrongPayload(t *testing.T) {
+	// Given
+	b := NewBroker()
+
+	var err error
+	Subscribe(b, userCreatedTopic, "send welcome email", func(ctx context.Context, e Envelope, payload userCreated) error {
+		return nil
+	})
+
+	// When
+	for _, sub := range b.handlers[userCreatedTopic.String()] {
+		err = sub.fn(context.Background(), Envelope{Name: userCreatedTopic.String(), Payload: "john@example.com"})
+	}
+
+	// Then
+	require.ErrorIs(t, err, ErrWrongPayload)
+}
+
+func TestSubscribe_JSONPayload(t *testing.T) {
+	// Given
+	b := NewBroker()
+
+	var got userCreated
+	Subscribe(b, userCreatedTopic, "send welcome email", func(ctx context.Context, e Envelope, payload userCreated) error {
+		got = payload
+		return nil
+	})
+
+	// When
+	var err error
+	for _, sub := range b.handlers[userCreatedTopic.String()] {
+		err = sub.fn(context.Background(), Envelope{
+			Name:    userCreatedTopic.String(),
+			Payload: json.RawMessage(`{"Email":"john@example.com"}`),
+		})
+	}
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, userCreated{Email: "john@example.com"}, got)
+}
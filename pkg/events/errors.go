@@ -0,0 +1,10 @@
+package events
+
+import (
+	"errors"
+)
+
+var (
+	ErrClosed       = errors.New("event bus is closed")
+	ErrWrongPayload = errors.New("event payload is of wrong type")
+)
@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Envelope representing an event with its metadata, the payload is the event data of the type of its topic
+type Envelope struct {
+	ID         string
+	Name       string
+	OccurredAt time.Time
+	// TraceContext the trace context of the publisher, the handlers spans are linked to it
+	TraceContext map[string]string
+	Payload      any
+}
+
+// Topic identifies the events of a name carrying a payload of type T
+//
+//	var UserCreatedTopic = events.NewTopic[UserCreated]("users.user_created")
+type Topic[T any] struct {
+	name string
+}
+
+// NewTopic creates a topic, the name must be unique across the modules
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{name: name}
+}
+
+func (t Topic[T]) String() string {
+	return t.name
+}
+
+// New creates an event of the topic occurring now, carrying the trace context of ctx
+func New[T any](ctx context.Context, topic Topic[T], payload T) Envelope {
+	e := Envelope{
+		ID:           newID(),
+		Name:         topic.name,
+		OccurredAt:   time.Now().UTC(),
+		TraceContext: map[string]string{},
+		Payload:      payload,
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(e.TraceContext))
+
+	return e
+}
+
+// Publish publishes an event of the topic to the bus
+func Publish[T any](ctx context.Context, bus Bus, topic Topic[T], payload T) error {
+	return bus.Publish(ctx, New(ctx, topic, payload))
+}
+
+// Subscribe subscribes the handler to the events of the topic, the name of the handler identifies it
+// in the logs and the traces. The payloads encoded in JSON, e.g. relayed from the outbox, are decoded
+// into the type of the topic.
+func Subscribe[T any](bus Bus, topic Topic[T], name string, fn func(ctx context.Context, e Envelope, payload T) error) {
+	bus.Subscribe(topic.name, name, func(ctx context.Context, e Envelope) error {
+		payload, err := decodePayload[T](e)
+		if err != nil {
+			return err
+		}
+
+		return fn(ctx, e, payload)
+	})
+}
+
+// decodePayload returns the payload of the event, the payloads of the events read from a store, e.g.
+// the outbox, are decoded from JSON
+func decodePayload[T any](e Envelope) (T, error) {
+	var payload T
+
+	switch p := e.Payload.(type) {
+	case T:
+		return p, nil
+	case json.RawMessage:
+		if err := json.Unmarshal(p, &payload); err != nil {
+			return payload, fmt.Errorf("%w: event `%s` payload cannot be decoded into %T: %v", ErrWrongPayload, e.Name, payload, err)
+		}
+		return payload, nil
+	default:
+		return payload, fmt.Errorf("%w: event `%s` payload is of type %T, not %T", ErrWrongPayload, e.Name, e.Payload, payload)
+	}
+}
+
+// newID returns a random 128 bits identifier in hex
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("generate event id error: %v", err))
+	}
+
+	return hex.EncodeToString(b[:])
+}
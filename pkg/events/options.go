@@ -0,0 +1,43 @@
+package events
+
+import (
+	"github.com/virsavik/alchemist-template/pkg/backoff"
+	"github.com/virsavik/alchemist-template/pkg/logger"
+)
+
+type Option func(b *Broker)
+
+func WithLogger(log logger.Logger) Option {
+	return func(b *Broker) {
+		b.logger = log
+	}
+}
+
+// BufferSize sets the number of events published and not yet delivered, Publish blocks when the buffer is full
+func BufferSize(n int) Option {
+	return func(b *Broker) {
+		if n > 0 {
+			b.bufferSize = n
+		}
+	}
+}
+
+// Concurrency sets the number of events delivered concurrently, the events are delivered in the publishing
+// order with the default concurrency of 1
+func Concurrency(n int) Option {
+	return func(b *Broker) {
+		if n > 0 {
+			b.concurrency = n
+		}
+	}
+}
+
+// Retry sets the number of times an event is delivered to a failing handler and the backoff between the attempts
+func Retry(maxAttempts int, bo backoff.Exponential) Option {
+	return func(b *Broker) {
+		if maxAttempts > 0 {
+			b.maxAttempts = maxAttempts
+		}
+		b.backoff = bo
+	}
+}
@@ -0,0 +1,17 @@
+package events
+
+import (
+	"context"
+)
+
+// Handler handles an event, the event is redelivered to the handler when it returns an error
+type Handler func(ctx context.Context, e Envelope) error
+
+// Bus publishes the events to the handlers subscribed to their name
+type Bus interface {
+	// Publish the events, they are delivered asynchronously to the handlers
+	Publish(ctx context.Context, events ...Envelope) error
+
+	// Subscribe the handler to the events of the name, the handler name identifies it in the logs and the traces
+	Subscribe(event string, name string, fn Handler)
+}
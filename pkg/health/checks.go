@@ -0,0 +1,31 @@
+package health
+
+import (
+	"context"
+	"net"
+)
+
+// Pinger is implemented by the components which can be pinged, e.g. *sql.DB
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Ping checks the component by pinging it
+func Ping(p Pinger) CheckFunc {
+	return func(ctx context.Context) error {
+		return p.PingContext(ctx)
+	}
+}
+
+// Dial checks a TCP address is reachable, e.g. the address of a telemetry collector
+func Dial(address string) CheckFunc {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+}
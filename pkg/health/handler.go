@@ -0,0 +1,26 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/virsavik/alchemist-template/pkg/rest/httpio"
+)
+
+// Handler serves the result of the checks of the kind as JSON, with a 200 OK status when the
+// checks are up and a 503 Service Unavailable status otherwise
+func Handler(r Registry, kind Kind) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		rs := r.Check(req.Context(), kind)
+
+		status := http.StatusOK
+		if rs.Status != StatusUp {
+			status = http.StatusServiceUnavailable
+		}
+
+		httpio.WriteJSON(w, req, httpio.Response[Result]{
+			Status:  status,
+			Headers: map[string]string{"Cache-Control": "no-store"},
+			Body:    rs,
+		})
+	}
+}
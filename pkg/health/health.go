@@ -0,0 +1,225 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout  = 2 * time.Second
+	defaultCacheTTL = time.Second
+)
+
+// ErrShuttingDown the readiness error once the shutdown started
+var ErrShuttingDown = errors.New("shutting down")
+
+// Kind the kind of check, a check can be of several kinds, e.g. Liveness | Readiness
+type Kind int
+
+const (
+	// Liveness checks fail when the process must be restarted, e.g. a deadlock
+	Liveness Kind = 1 << iota
+	// Readiness checks fail when the process cannot serve traffic, e.g. the database is not reachable
+	Readiness
+)
+
+// Status the status of a check
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// CheckFunc checks a component, it returns an error when the component is unhealthy
+type CheckFunc func(ctx context.Context) error
+
+// Registry holds the checks of the application components, the system registers the checks of its
+// components and the modules register their own
+type Registry interface {
+	// Register a check, the name must be unique
+	Register(name string, kind Kind, fn CheckFunc, opts ...CheckOption)
+
+	// Check runs the checks of the kind concurrently, the results are cached for the cache TTL of each check
+	Check(ctx context.Context, kind Kind) Result
+
+	// ShutDown flips the readiness to failing, so that the load balancers stop sending traffic
+	ShutDown()
+}
+
+// Result representing the result of the checks of a kind
+type Result struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// CheckResult representing the result of a check
+type CheckResult struct {
+	Status    Status    `json:"status"`
+	Optional  bool      `json:"optional,omitempty"`
+	Latency   string    `json:"latency"`
+	CheckedAt time.Time `json:"checkedAt"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type registry struct {
+	mu           sync.RWMutex
+	checks       map[string]*check
+	shuttingDown bool
+}
+
+// New initializes a health check registry
+func New() Registry {
+	return &registry{
+		checks: make(map[string]*check),
+	}
+}
+
+func (r *registry) Register(name string, kind Kind, fn CheckFunc, opts ...CheckOption) {
+	c := &check{
+		name:     name,
+		kind:     kind,
+		fn:       fn,
+		timeout:  defaultTimeout,
+		cacheTTL: defaultCacheTTL,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.checks[name]; exists {
+		panic(fmt.Sprintf("health check `%s` is already registered", name))
+	}
+
+	r.checks[name] = c
+}
+
+func (r *registry) Check(ctx context.Context, kind Kind) Result {
+	r.mu.RLock()
+	shuttingDown := r.shuttingDown
+	var checks []*check
+	for _, c := range r.checks {
+		if c.kind&kind != 0 {
+			checks = append(checks, c)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].name < checks[j].name
+	})
+
+	rs := Result{
+		Status: StatusUp,
+		Checks: make(map[string]CheckResult, len(checks)),
+	}
+
+	if shuttingDown && kind&Readiness != 0 {
+		rs.Status = StatusDown
+		rs.Checks["shutdown"] = CheckResult{
+			Status:    StatusDown,
+			Latency:   "0s",
+			CheckedAt: time.Now(),
+			Error:     ErrShuttingDown.Error(),
+		}
+	}
+
+	results := make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+	for idx, c := range checks {
+		idx, c := idx, c // Avoid closure capture
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[idx] = c.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	for idx, c := range checks {
+		rs.Checks[c.name] = results[idx]
+		if results[idx].Status == StatusDown && !c.optional {
+			rs.Status = StatusDown
+		}
+	}
+
+	return rs
+}
+
+func (r *registry) ShutDown() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.shuttingDown = true
+}
+
+type check struct {
+	name     string
+	kind     Kind
+	fn       CheckFunc
+	timeout  time.Duration
+	cacheTTL time.Duration
+	optional bool
+
+	mu     sync.Mutex
+	cached CheckResult
+}
+
+// run runs the check unless the cached result is fresh, concurrent runs wait for the running one
+func (c *check) run(ctx context.Context) CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.cached.CheckedAt.IsZero() && time.Since(c.cached.CheckedAt) < c.cacheTTL {
+		return c.cached
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	started := time.Now()
+	err := c.call(ctx)
+
+	rs := CheckResult{
+		Status:    StatusUp,
+		Optional:  c.optional,
+		Latency:   time.Since(started).String(),
+		CheckedAt: started,
+	}
+	if err != nil {
+		rs.Status, rs.Error = StatusDown, err.Error()
+	}
+
+	c.cached = rs
+
+	return rs
+}
+
+// call calls the check, a check which does not return before the timeout fails
+func (c *check) call(ctx context.Context) (err error) {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+		done <- c.fn(ctx)
+	}()
+
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,133 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Check(t *testing.T) {
+	errDown := errors.New("connection refused")
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errDown }
+	hang := func(ctx context.Context) error { select {} }
+
+	tcs := map[string]struct {
+		setup     func(r Registry)
+		kind      Kind
+		expStatus Status
+		expChecks map[string]Status
+	}{
+		"no checks": {
+			setup:     func(r Registry) {},
+			kind:      Liveness,
+			expStatus: StatusUp,
+			expChecks: map[string]Status{},
+		},
+		"checks of the kind only": {
+			setup: func(r Registry) {
+				r.Register("db", Readiness, down)
+				r.Register("loop", Liveness|Readiness, up)
+			},
+			kind:      Liveness,
+			expStatus: StatusUp,
+			expChecks: map[string]Status{"loop": StatusUp},
+		},
+		"required check down": {
+			setup: func(r Registry) {
+				r.Register("db", Readiness, down)
+				r.Register("loop", Liveness|Readiness, up)
+			},
+			kind:      Readiness,
+			expStatus: StatusDown,
+			expChecks: map[string]Status{"db": StatusDown, "loop": StatusUp},
+		},
+		"optional check down": {
+			setup: func(r Registry) {
+				r.Register("collector", Readiness, down, Optional())
+			},
+			kind:      Readiness,
+			expStatus: StatusUp,
+			expChecks: map[string]Status{"collector": StatusDown},
+		},
+		"timeout": {
+			setup: func(r Registry) {
+				r.Register("db", Readiness, hang, Timeout(10*time.Millisecond))
+			},
+			kind:      Readiness,
+			expStatus: StatusDown,
+			expChecks: map[string]Status{"db": StatusDown},
+		},
+		"shutting down": {
+			setup: func(r Registry) {
+				r.Register("db", Readiness, up)
+				r.ShutDown()
+			},
+			kind:      Readiness,
+			expStatus: StatusDown,
+			expChecks: map[string]Status{"db": StatusUp, "shutdown": StatusDown},
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			r := New()
+			tc.setup(r)
+
+			// When
+			rs := r.Check(context.Background(), tc.kind)
+
+			// Then
+			require.Equal(t, tc.expStatus, rs.Status)
+			checks := map[string]Status{}
+			for name, c := range rs.Checks {
+				checks[name] = c.Status
+			}
+			require.Equal(t, tc.expChecks, checks)
+		})
+	}
+}
+
+func TestRegistry_CheckCached(t *testing.T) {
+	// Given
+	runs := 0
+	r := New()
+	r.Register("db", Readiness, func(ctx context.Context) error {
+		runs++
+		return nil
+	}, CacheTTL(time.Minute))
+
+	// When
+	r.Check(context.Background(), Readiness)
+	r.Check(context.Background(), Readiness)
+
+	// Then
+	require.Equal(t, 1, runs)
+}
+
+func TestHandler(t *testing.T) {
+	// Given
+	r := New()
+	r.Register("db", Readiness, func(ctx context.Context) error { return nil })
+	hdl := Handler(r, Readiness)
+
+	// When
+	before := httptest.NewRecorder()
+	hdl(before, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	r.ShutDown()
+	after := httptest.NewRecorder()
+	hdl(after, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	// Then
+	require.Equal(t, http.StatusOK, before.Code)
+	require.Contains(t, before.Body.String(), `"status":"up"`)
+	require.Equal(t, http.StatusServiceUnavailable, after.Code)
+	require.Contains(t, after.Body.String(), `"error":"shutting down"`)
+}
@@ -0,0 +1,32 @@
+package health
+
+import (
+	"time"
+)
+
+type CheckOption func(c *check)
+
+// Timeout sets the time the check has to complete, the check fails when it is exceeded
+func Timeout(d time.Duration) CheckOption {
+	return func(c *check) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// CacheTTL sets the time the result of the check is reused, so that frequent probes do not
+// overload the checked component
+func CacheTTL(d time.Duration) CheckOption {
+	return func(c *check) {
+		c.cacheTTL = d
+	}
+}
+
+// Optional reports the result of the check without failing the checks of its kind, e.g. the
+// telemetry exporter being unreachable does not prevent serving traffic
+func Optional() CheckOption {
+	return func(c *check) {
+		c.optional = true
+	}
+}
@@ -8,7 +8,12 @@ import (
 // AuthHeader is a token extractor that takes a request
 // and extracts the token from the Authorization header.
 func AuthHeader(r *http.Request) (string, error) {
-	authHeader := r.Header.Get("Authorization")
+	return BearerToken(r.Header.Get("Authorization"))
+}
+
+// BearerToken extracts the token from the value of an authorization header,
+// e.g. the authorization metadata of a gRPC request.
+func BearerToken(authHeader string) (string, error) {
 	if authHeader == "" {
 		return "", nil // No error, just no JWT.
 	}
@@ -20,6 +20,9 @@ const (
 	defaultRequestTimeout  = 10 * time.Second
 )
 
+// ErrKeysNotCached the JWKS has not been downloaded yet
+var ErrKeysNotCached = errors.New("jwks not cached")
+
 type CacheProvider struct {
 	issuer          url.URL
 	jwksURI         url.URL
@@ -68,6 +71,15 @@ func (c *CacheProvider) GetPublicKeys() jwk.Set {
 	return c.cache
 }
 
+// Check checks the JWKS has been cached, the tokens cannot be validated until then
+func (c *CacheProvider) Check(ctx context.Context) error {
+	if c.GetPublicKeys() == nil {
+		return ErrKeysNotCached
+	}
+
+	return nil
+}
+
 func (c *CacheProvider) fetchPublicKeys(ctx context.Context) (jwk.Set, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.jwksURI.String(), nil)
 	if err != nil {
@@ -97,38 +109,39 @@ func (c *CacheProvider) storePublicKeys(jwks jwk.Set) {
 	c.cache = jwks
 }
 
+// FetchLoop downloads and caches the JWKS until ctx is done. A failed download keeps the cached keys,
+// the error is returned when no keys have been cached yet so that the loop is restarted by its supervisor.
 func (c *CacheProvider) FetchLoop(ctx context.Context) error {
 	log := c.logger
 	log.Infof("Starting fetch JWKS loop...")
 
 	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
 
-	// Intentionally not having a separate func to control polling as we don't need to care about graceful shutdown for this poller or explicitly handle cleanup
-	go func() {
-		for {
-			log.Infof("Attempting download and caching JWKS...")
-
-			newCtx := logger.NewCtx(ctx)
-
-			// Fetch JWKS
-			jwks, err := c.fetchPublicKeys(newCtx)
-			if err != nil {
-				log.Errorf(err, "download and caching failed")
-			} else {
-				log.Infof("Download and caching complete")
-			}
+	for {
+		log.Infof("Attempting download and caching JWKS...")
 
+		// Fetch JWKS
+		jwks, err := c.fetchPublicKeys(logger.NewCtx(ctx))
+		switch {
+		case err == nil:
 			// Cache JWKS
 			c.storePublicKeys(jwks)
-
-			<-ticker.C
+			log.Infof("Download and caching complete")
+		case ctx.Err() != nil:
+			// Shutting down
+		case c.GetPublicKeys() == nil:
+			log.Errorf(err, "download and caching failed, no keys cached")
+			return err
+		default:
+			log.Errorf(err, "download and caching failed, keeping the cached keys")
 		}
-	}()
-
-	<-ctx.Done()
-
-	log.Infof("Stopping JWKS fetch loop...")
 
-	ticker.Stop()
-	return nil
+		select {
+		case <-ctx.Done():
+			log.Infof("Stopping JWKS fetch loop...")
+			return nil
+		case <-ticker.C:
+		}
+	}
 }
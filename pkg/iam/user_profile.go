@@ -1,11 +1,27 @@
 package iam
 
 import (
+	"strings"
+
 	"github.com/lestrrat-go/jwx/v2/jwt"
 )
 
 type UserProfile struct {
 	ID string
+	// Scopes the scopes granted to the user, from the space separated `scope` claim and the
+	// `permissions` claim of the role based access control
+	Scopes []string
+}
+
+// HasScope reports whether the scope is granted to the user
+func (p UserProfile) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
 }
 
 // GetUserProfile returns UserProfile by given token
@@ -21,6 +37,29 @@ func GetUserProfile(token jwt.Token) (UserProfile, error) {
 	}
 
 	return UserProfile{
-		ID: id,
+		ID:     id,
+		Scopes: getScopes(token),
 	}, nil
 }
+
+// getScopes returns the scopes of the token, the claims of an unexpected type are ignored
+func getScopes(token jwt.Token) []string {
+	var scopes []string
+	if v, ok := token.Get("scope"); ok {
+		if s, ok := v.(string); ok {
+			scopes = append(scopes, strings.Fields(s)...)
+		}
+	}
+
+	if v, ok := token.Get("permissions"); ok {
+		if ps, ok := v.([]interface{}); ok {
+			for _, p := range ps {
+				if s, ok := p.(string); ok {
+					scopes = append(scopes, s)
+				}
+			}
+		}
+	}
+
+	return scopes
+}
@@ -0,0 +1,11 @@
+package jobs
+
+import (
+	"errors"
+)
+
+var (
+	ErrDuplicated = errors.New("job is already enqueued")
+	ErrNotFound   = errors.New("job not found")
+	ErrWrongArgs  = errors.New("job args are of wrong type")
+)
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Kind identifies the jobs of a name carrying args of type T
+//
+//	var SendWelcomeEmailKind = jobs.NewKind[SendWelcomeEmail]("users.send_welcome_email")
+type Kind[T any] struct {
+	name string
+}
+
+// NewKind creates a kind, the name must be unique across the modules
+func NewKind[T any](name string) Kind[T] {
+	return Kind[T]{name: name}
+}
+
+func (k Kind[T]) String() string {
+	return k.name
+}
+
+// Enqueue enqueues a job of the kind, see Queue.Enqueue
+func Enqueue[T any](ctx context.Context, q Queue, kind Kind[T], args T, opts ...EnqueueOption) (string, error) {
+	return q.Enqueue(ctx, kind.name, args, opts...)
+}
+
+// Handle registers the handler of the jobs of the kind, the args of the jobs are decoded into the type
+// of the kind. A job whose args cannot be decoded fails.
+func Handle[T any](q Queue, kind Kind[T], fn func(ctx context.Context, job Job, args T) error, opts ...HandlerOption) {
+	q.Handle(kind.name, func(ctx context.Context, job Job) error {
+		var args T
+		if err := json.Unmarshal(job.Args, &args); err != nil {
+			return fmt.Errorf("%w: job `%s` args cannot be decoded into %T: %v", ErrWrongArgs, job.Kind, args, err)
+		}
+
+		return fn(ctx, job, args)
+	}, opts...)
+}
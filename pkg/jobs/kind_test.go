@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type sendEmail struct {
+	To string `json:"to"`
+}
+
+// fakeQueue runs the jobs enqueued right away with their handler
+type fakeQueue struct {
+	handlers map[string]Handler
+}
+
+func (q *fakeQueue) Enqueue(ctx context.Context, kind string, args any, opts ...EnqueueOption) (string, error) {
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+
+	return "1", q.handlers[kind](ctx, Job{ID: "1", Kind: kind, Args: encoded})
+}
+
+func (q *fakeQueue) Handle(kind string, fn Handler, opts ...HandlerOption) {
+	q.handlers[kind] = fn
+}
+
+func TestHandle(t *testing.T) {
+	// Given
+	kind := NewKind[sendEmail]("users.send_email")
+	q := &fakeQueue{handlers: map[string]Handler{}}
+
+	var handled sendEmail
+	Handle(q, kind, func(ctx context.Context, job Job, args sendEmail) error {
+		handled = args
+		return nil
+	})
+
+	// When
+	id, err := Enqueue(context.Background(), q, kind, sendEmail{To: "john@example.com"})
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, "1", id)
+	require.Equal(t, sendEmail{To: "john@example.com"}, handled)
+}
+
+func TestHandle_WrongArgs(t *testing.T) {
+	// Given
+	q := &fakeQueue{handlers: map[string]Handler{}}
+	Handle(q, NewKind[sendEmail]("users.send_email"), func(ctx context.Context, job Job, args sendEmail) error {
+		return nil
+	})
+
+	// When
+	_, err := q.Enqueue(context.Background(), "users.send_email", []int{1})
+
+	// Then
+	require.ErrorIs(t, err, ErrWrongArgs)
+}
@@ -0,0 +1,100 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/virsavik/alchemist-template/pkg/backoff"
+	"github.com/virsavik/alchemist-template/pkg/logger"
+)
+
+type Option func(p *Processor)
+
+func WithLogger(log logger.Logger) Option {
+	return func(p *Processor) {
+		p.logger = log
+	}
+}
+
+// Concurrency sets the max number of jobs run at once by the processor
+func Concurrency(n int) Option {
+	return func(p *Processor) {
+		if n > 0 {
+			p.concurrency = n
+		}
+	}
+}
+
+// PollInterval sets the interval the queue is polled at when there is no job to run
+func PollInterval(d time.Duration) Option {
+	return func(p *Processor) {
+		if d > 0 {
+			p.pollInterval = d
+		}
+	}
+}
+
+// Lease sets the time a job is locked by the processor running it, a job still running once its lease
+// expired, e.g. its processor crashed, is run again by the processors. The runs are canceled once the
+// lease expires.
+func Lease(d time.Duration) Option {
+	return func(p *Processor) {
+		if d > 0 {
+			p.lease = d
+		}
+	}
+}
+
+// WithBackoff sets the backoff of the retries of the failed jobs
+func WithBackoff(b backoff.Exponential) Option {
+	return func(p *Processor) {
+		p.backoff = b
+	}
+}
+
+type EnqueueOption func(o *enqueueOptions)
+
+type enqueueOptions struct {
+	runAt       time.Time
+	uniqueKey   string
+	maxAttempts int
+}
+
+// RunAt delays the job until t
+func RunAt(t time.Time) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.runAt = t
+	}
+}
+
+// Delay delays the job for d
+func Delay(d time.Duration) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.runAt = time.Now().Add(d)
+	}
+}
+
+// Unique deduplicates the job by the key, the job is not enqueued while a job of the same kind and key
+// is pending or running, Enqueue returns ErrDuplicated instead
+func Unique(key string) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.uniqueKey = key
+	}
+}
+
+// MaxAttempts sets the number of runs of the job before it is dead
+func MaxAttempts(n int) EnqueueOption {
+	return func(o *enqueueOptions) {
+		if n > 0 {
+			o.maxAttempts = n
+		}
+	}
+}
+
+type HandlerOption func(h *handler)
+
+// Timeout cancels the context of a run once d elapsed, the runs are bounded by the lease of the processor
+func Timeout(d time.Duration) HandlerOption {
+	return func(h *handler) {
+		h.timeout = d
+	}
+}
@@ -0,0 +1,559 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/virsavik/alchemist-template/pkg/backoff"
+	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/metrics"
+	"github.com/virsavik/alchemist-template/pkg/postgres"
+)
+
+const (
+	scopeName = "github.com/virsavik/alchemist-template/pkg/jobs"
+
+	defaultConcurrency  = 10
+	defaultPollInterval = time.Second
+	defaultLease        = 5 * time.Minute
+	defaultMaxAttempts  = 25
+
+	// finishTimeout bounds the recording of the outcome of a run, which is not canceled with the run
+	finishTimeout = 10 * time.Second
+
+	// uniqueViolation the code of the postgres error raised when a unique index is violated
+	uniqueViolation = "23505"
+)
+
+var _ Queue = (*Processor)(nil)
+
+// Processor enqueues the jobs into the jobs table and runs them with the registered handlers. The jobs
+// are claimed with a lease, skipping the jobs claimed by the other processors, so that several
+// replicas share the queue without running a job twice at once.
+//
+// A failed job is retried with an exponential backoff, it is dead once its attempts are exhausted and
+// stays in the queue until it is replayed, see Replay.
+//
+// The runs are not canceled when Run returns, they complete during the shutdown until the deadline of
+// Close, their context is canceled then and the jobs are released back to the queue without counting
+// the attempt.
+type Processor struct {
+	db           *sql.DB
+	logger       logger.Logger
+	tracer       trace.Tracer
+	concurrency  int
+	pollInterval time.Duration
+	lease        time.Duration
+	backoff      backoff.Exponential
+
+	mu       sync.RWMutex
+	handlers map[string]handler
+	closed   bool
+
+	// slots the semaphore bounding the jobs running at once
+	slots chan struct{}
+	// jobsCtx is the context of the runs, it is canceled when the runs overrun the deadline of Close
+	jobsCtx    context.Context
+	cancelJobs context.CancelFunc
+	running    sync.WaitGroup
+
+	completed metrics.Counter
+	failed    metrics.Counter
+	dead      metrics.Counter
+	duration  metrics.Histogram
+}
+
+type handler struct {
+	fn      Handler
+	timeout time.Duration
+}
+
+// NewProcessor creates a processor of the jobs of the database
+func NewProcessor(db *sql.DB, opts ...Option) *Processor {
+	jobsCtx, cancel := context.WithCancel(context.Background())
+
+	p := &Processor{
+		db:           db,
+		logger:       logger.NewNoop(),
+		tracer:       otel.Tracer(scopeName),
+		concurrency:  defaultConcurrency,
+		pollInterval: defaultPollInterval,
+		lease:        defaultLease,
+		backoff:      backoff.Exponential{Initial: 10 * time.Second, Max: time.Hour},
+		handlers:     make(map[string]handler),
+		jobsCtx:      jobsCtx,
+		cancelJobs:   cancel,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.slots = make(chan struct{}, p.concurrency)
+
+	meter := metrics.NewMeter(scopeName)
+	p.completed = meter.Counter("jobs.completed", "Number of jobs completed")
+	p.failed = meter.Counter("jobs.failed", "Number of job runs failed and retried")
+	p.dead = meter.Counter("jobs.dead", "Number of jobs dead once their attempts are exhausted")
+	p.duration = meter.Histogram("jobs.duration", "Duration of the job runs", "s")
+
+	return p
+}
+
+func (p *Processor) Enqueue(ctx context.Context, kind string, args any, opts ...EnqueueOption) (string, error) {
+	o := enqueueOptions{runAt: time.Now(), maxAttempts: defaultMaxAttempts}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return "", fmt.Errorf("marshal job `%s` args: %w", kind, err)
+	}
+
+	// The run is linked to the trace of the enqueuer
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	traceContext, err := json.Marshal(carrier)
+	if err != nil {
+		return "", fmt.Errorf("marshal job `%s` trace context: %w", kind, err)
+	}
+
+	uniqueKey := sql.NullString{String: o.uniqueKey, Valid: o.uniqueKey != ""}
+
+	id := newID()
+	err = postgres.Trace(postgres.ExecutorFromCtx(ctx, p.db)).QueryRowContext(ctx,
+		`INSERT INTO "jobs" ("id", "kind", "args", "trace_context", "unique_key", "max_attempts", "run_at")
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT ("kind", "unique_key") WHERE "unique_key" IS NOT NULL AND "state" IN ('pending', 'running')
+		DO NOTHING
+		RETURNING "id"`,
+		id, kind, encoded, traceContext, uniqueKey, o.maxAttempts, o.runAt,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: job `%s` with the unique key `%s`", ErrDuplicated, kind, o.uniqueKey)
+	}
+	if err != nil {
+		return "", fmt.Errorf("enqueue job `%s`: %w", kind, err)
+	}
+
+	return id, nil
+}
+
+func (p *Processor) Handle(kind string, fn Handler, opts ...HandlerOption) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.handlers[kind]; exists {
+		panic(fmt.Sprintf("job `%s` is already handled", kind))
+	}
+
+	h := handler{fn: fn}
+	for _, opt := range opts {
+		opt(&h)
+	}
+	p.handlers[kind] = h
+}
+
+// Run claims and runs the jobs until ctx is done or the processor is closed, a poll claiming as many jobs
+// as there are free workers is followed by the next one without waiting. The runs in progress are waited
+// by Close.
+func (p *Processor) Run(ctx context.Context) error {
+	for {
+		if p.isClosed() {
+			return nil
+		}
+
+		free := p.acquire(ctx)
+		if free == 0 {
+			return nil
+		}
+
+		n, err := p.poll(ctx, free)
+		if err != nil && ctx.Err() == nil {
+			p.logger.Errorf(err, "claim jobs failed")
+		}
+
+		// The workers not given a job are freed
+		for i := n; i < free; i++ {
+			<-p.slots
+		}
+
+		if n == free && err == nil {
+			continue
+		}
+
+		timer := time.NewTimer(p.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// Close waits for the runs in progress, their context is canceled once ctx is done and the error of ctx
+// is returned
+func (p *Processor) Close(ctx context.Context) error {
+	// No run starts once closed, so that the runs waited are the runs in progress
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancelJobs()
+		return nil
+	case <-ctx.Done():
+		p.cancelJobs()
+		return ctx.Err()
+	}
+}
+
+// Dead returns the most recently dead jobs, up to limit
+func (p *Processor) Dead(ctx context.Context, limit int) ([]Job, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT "id", "kind", "args", "state", "attempts", "max_attempts", COALESCE("last_error", ''), "created_at"
+		FROM "jobs"
+		WHERE "state" = 'dead'
+		ORDER BY "updated_at" DESC
+		LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var js []Job
+	for rows.Next() {
+		var j Job
+		if err = rows.Scan(&j.ID, &j.Kind, &j.Args, &j.State, &j.Attempt, &j.MaxAttempts, &j.LastError, &j.CreatedAt); err != nil {
+			return nil, err
+		}
+		js = append(js, j)
+	}
+
+	return js, rows.Err()
+}
+
+// Replay enqueues the dead job again with its attempts reset, it returns ErrNotFound when there is no
+// dead job of the id and ErrDuplicated when a job of the same unique key is already enqueued
+func (p *Processor) Replay(ctx context.Context, id string) error {
+	res, err := p.db.ExecContext(ctx,
+		`UPDATE "jobs" SET "state" = 'pending', "attempts" = 0, "run_at" = NOW(), "updated_at" = NOW()
+		WHERE "id" = $1 AND "state" = 'dead'`,
+		id,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return fmt.Errorf("%w: job `%s` has the unique key of an enqueued job", ErrDuplicated, id)
+		}
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w: no dead job `%s`", ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// Purge deletes the jobs completed before the given time, returning their count
+func (p *Processor) Purge(ctx context.Context, completedBefore time.Time) (int64, error) {
+	res, err := p.db.ExecContext(ctx,
+		`DELETE FROM "jobs" WHERE "state" = 'completed' AND "completed_at" < $1`,
+		completedBefore,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// acquire waits for a free worker and takes the other free ones, it returns the number of workers
+// taken, 0 when ctx is done first
+func (p *Processor) acquire(ctx context.Context) int {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return 0
+	}
+
+	n := 1
+	for n < p.concurrency {
+		select {
+		case p.slots <- struct{}{}:
+			n++
+		default:
+			return n
+		}
+	}
+
+	return n
+}
+
+// poll claims up to limit jobs and starts running them, it returns the number of jobs started
+func (p *Processor) poll(ctx context.Context, limit int) (int, error) {
+	p.mu.RLock()
+	kinds := make([]string, 0, len(p.handlers))
+	for kind := range p.handlers {
+		kinds = append(kinds, kind)
+	}
+	p.mu.RUnlock()
+
+	if len(kinds) == 0 {
+		return 0, nil
+	}
+
+	// The jobs are not claimed once closed, the jobs claimed while closing are released back
+	if p.isClosed() {
+		return 0, nil
+	}
+
+	js, err := p.claim(ctx, kinds, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return 0, p.release(js)
+	}
+
+	for _, j := range js {
+		j, h := j, p.handlers[j.Kind]
+
+		p.running.Add(1)
+		go func() {
+			defer func() { <-p.slots }()
+			defer p.running.Done()
+
+			p.process(j, h)
+		}()
+	}
+
+	return len(js), nil
+}
+
+func (p *Processor) isClosed() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.closed
+}
+
+// claim locks the jobs due to run, or whose lease expired, for the lease of the processor
+func (p *Processor) claim(ctx context.Context, kinds []string, limit int) ([]Job, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`UPDATE "jobs" SET "state" = 'running', "attempts" = "attempts" + 1,
+			"locked_until" = NOW() + make_interval(secs => $3), "updated_at" = NOW()
+		WHERE "id" IN (
+			SELECT "id" FROM "jobs"
+			WHERE "kind" = ANY($1)
+				AND (("state" = 'pending' AND "run_at" <= NOW()) OR ("state" = 'running' AND "locked_until" < NOW()))
+			ORDER BY "run_at"
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING "id", "kind", "args", "trace_context", "state", "attempts", "max_attempts",
+			COALESCE("last_error", ''), "created_at"`,
+		kinds, limit, p.lease.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var js []Job
+	for rows.Next() {
+		var (
+			j            Job
+			traceContext []byte
+		)
+		if err = rows.Scan(&j.ID, &j.Kind, &j.Args, &traceContext, &j.State, &j.Attempt, &j.MaxAttempts,
+			&j.LastError, &j.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		if err = json.Unmarshal(traceContext, &j.traceContext); err != nil {
+			return nil, fmt.Errorf("unmarshal job `%s` trace context: %w", j.ID, err)
+		}
+
+		js = append(js, j)
+	}
+
+	return js, rows.Err()
+}
+
+// release gives back the claimed jobs not run, without counting the attempt
+func (p *Processor) release(js []Job) error {
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	for _, j := range js {
+		if _, err := p.db.ExecContext(ctx,
+			`UPDATE "jobs" SET "state" = 'pending', "attempts" = "attempts" - 1, "locked_until" = NULL, "updated_at" = NOW()
+			WHERE "id" = $1`,
+			j.ID,
+		); err != nil {
+			return fmt.Errorf("release job `%s`: %w", j.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// process runs the job within a span linked to its enqueuer, with a logger of the run on the context,
+// and records its outcome
+func (p *Processor) process(j Job, h handler) {
+	attrs := []attribute.KeyValue{
+		attribute.String("job.id", j.ID),
+		attribute.String("job.kind", j.Kind),
+		attribute.Int("job.attempt", j.Attempt),
+	}
+
+	ctx := otel.GetTextMapPropagator().Extract(p.jobsCtx, propagation.MapCarrier(j.traceContext))
+	ctx, span := p.tracer.Start(ctx, "jobs.run "+j.Kind,
+		trace.WithAttributes(attrs...),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+	)
+	defer span.End()
+
+	log := p.logger.With(
+		logger.String("job.id", j.ID),
+		logger.String("job.kind", j.Kind),
+		logger.Int("job.attempt", j.Attempt),
+		logger.String("trace.id", span.SpanContext().TraceID().String()),
+	)
+	ctx = logger.SetInCtx(ctx, log)
+
+	// The run is canceled before its lease expires, so that it is not run twice at once
+	timeout := p.lease
+	if h.timeout > 0 && h.timeout < timeout {
+		timeout = h.timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var err error
+	if j.Attempt > j.MaxAttempts {
+		// The lease of the last attempt expired, e.g. its processor crashed
+		err = errors.New("attempts are exhausted")
+	} else {
+		started := time.Now()
+		err = call(ctx, h.fn, j)
+		p.duration.Record(ctx, time.Since(started).Seconds(), attribute.String("job.kind", j.Kind))
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	// The run overran the deadline of Close, the job is run again by the next processor
+	if err != nil && p.jobsCtx.Err() != nil {
+		log.Warnf("job `%s` is canceled by the shutdown, releasing it: %v", j.Kind, err)
+		if releaseErr := p.release([]Job{j}); releaseErr != nil {
+			log.Errorf(releaseErr, "release job `%s` failed", j.Kind)
+		}
+		return
+	}
+
+	if finishErr := p.finish(j, err, log); finishErr != nil {
+		log.Errorf(finishErr, "record job `%s` outcome failed", j.Kind)
+	}
+}
+
+// finish marks the job completed, pending for a retry or dead once its attempts are exhausted
+func (p *Processor) finish(j Job, runErr error, log logger.Logger) error {
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	kind := attribute.String("job.kind", j.Kind)
+
+	switch {
+	case runErr == nil:
+		p.completed.Add(ctx, 1, kind)
+		log.Infof("job `%s` completed", j.Kind)
+
+		_, err := p.db.ExecContext(ctx,
+			`UPDATE "jobs" SET "state" = 'completed', "completed_at" = NOW(), "locked_until" = NULL,
+				"last_error" = NULL, "updated_at" = NOW()
+			WHERE "id" = $1`,
+			j.ID,
+		)
+		return err
+	case j.Attempt >= j.MaxAttempts:
+		p.dead.Add(ctx, 1, kind)
+		log.Errorf(runErr, "job `%s` is dead after %d attempts", j.Kind, j.Attempt)
+
+		_, err := p.db.ExecContext(ctx,
+			`UPDATE "jobs" SET "state" = 'dead', "locked_until" = NULL, "last_error" = $2, "updated_at" = NOW()
+			WHERE "id" = $1`,
+			j.ID, runErr.Error(),
+		)
+		return err
+	default:
+		runAt := time.Now().Add(p.backoff.Delay(j.Attempt - 1))
+
+		p.failed.Add(ctx, 1, kind)
+		log.Warnf("job `%s` failed, retrying at %s: %v", j.Kind, runAt.Format(time.RFC3339), runErr)
+
+		_, err := p.db.ExecContext(ctx,
+			`UPDATE "jobs" SET "state" = 'pending', "run_at" = $2, "locked_until" = NULL, "last_error" = $3,
+				"updated_at" = NOW()
+			WHERE "id" = $1`,
+			j.ID, runAt, runErr.Error(),
+		)
+		return err
+	}
+}
+
+// call calls the handler, recovering its panic as an error
+func call(ctx context.Context, fn Handler, j Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return fn(ctx, j)
+}
+
+// newID returns a random 128 bits identifier in hex
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("generate job id error: %v", err))
+	}
+
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,338 @@
+package jobs
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+
+	"github.com/virsavik/alchemist-template/pkg/backoff"
+)
+
+// anyConverter accepts the args of any type, e.g. the kinds of the claimed jobs bound as an array
+type anyConverter struct{}
+
+func (anyConverter) ConvertValue(v any) (driver.Value, error) {
+	return v, nil
+}
+
+// timeBetween matches a time within the bounds
+type timeBetween struct {
+	from, to time.Time
+}
+
+func (m timeBetween) Match(v driver.Value) bool {
+	t, ok := v.(time.Time)
+	return ok && !t.Before(m.from) && !t.After(m.to)
+}
+
+func newTestProcessor(t *testing.T, opts ...Option) (*Processor, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyConverter{}))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return NewProcessor(db, opts...), mock
+}
+
+var claimedColumns = []string{"id", "kind", "args", "trace_context", "state", "attempts", "max_attempts",
+	"last_error", "created_at"}
+
+func TestProcessor_Enqueue(t *testing.T) {
+	runAt := time.Date(2026, 10, 18, 4, 0, 0, 0, time.UTC)
+
+	tcs := map[string]struct {
+		opts   []EnqueueOption
+		mockFn func(mock sqlmock.Sqlmock)
+		expErr error
+	}{
+		"enqueued": {
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "jobs"`)).
+					WithArgs(sqlmock.AnyArg(), "send welcome email", []byte(`{"to":"john@example.com"}`),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), defaultMaxAttempts, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+			},
+		},
+		"delayed": {
+			opts: []EnqueueOption{RunAt(runAt), MaxAttempts(3)},
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "jobs"`)).
+					WithArgs(sqlmock.AnyArg(), "send welcome email", sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), 3, runAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+			},
+		},
+		"duplicated": {
+			opts: []EnqueueOption{Unique("john@example.com")},
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`ON CONFLICT ("kind", "unique_key")`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			expErr: ErrDuplicated,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			p, mock := newTestProcessor(t)
+			tc.mockFn(mock)
+
+			// When
+			id, err := p.Enqueue(context.Background(), "send welcome email", sendEmail{To: "john@example.com"}, tc.opts...)
+
+			// Then
+			require.NoError(t, mock.ExpectationsWereMet())
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "1", id)
+		})
+	}
+}
+
+func TestProcessor_Finish(t *testing.T) {
+	b := backoff.Exponential{Initial: time.Minute, Max: time.Hour, Multiplier: 2, Jitter: 0.1}
+
+	tcs := map[string]struct {
+		job    Job
+		runErr error
+		mockFn func(mock sqlmock.Sqlmock)
+	}{
+		"completed": {
+			job: Job{ID: "1", Kind: "send welcome email", Attempt: 1, MaxAttempts: 3},
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(`SET "state" = 'completed'`)).
+					WithArgs("1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		"retried with backoff": {
+			job:    Job{ID: "1", Kind: "send welcome email", Attempt: 2, MaxAttempts: 3},
+			runErr: errors.New("smtp unavailable"),
+			mockFn: func(mock sqlmock.Sqlmock) {
+				// The second retry is delayed by 2 minutes, with a jitter of 10%
+				now := time.Now()
+				mock.ExpectExec(regexp.QuoteMeta(`SET "state" = 'pending', "run_at" = $2`)).
+					WithArgs("1", timeBetween{from: now.Add(108 * time.Second), to: now.Add(133 * time.Second)}, "smtp unavailable").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		"dead once the attempts are exhausted": {
+			job:    Job{ID: "1", Kind: "send welcome email", Attempt: 3, MaxAttempts: 3},
+			runErr: errors.New("smtp unavailable"),
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(`SET "state" = 'dead'`)).
+					WithArgs("1", "smtp unavailable").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			p, mock := newTestProcessor(t, WithBackoff(b))
+			tc.mockFn(mock)
+
+			// When
+			err := p.finish(tc.job, tc.runErr, p.logger)
+
+			// Then
+			require.NoError(t, err)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestProcessor_Process(t *testing.T) {
+	tcs := map[string]struct {
+		fn       Handler
+		shutdown bool
+		mockFn   func(mock sqlmock.Sqlmock)
+	}{
+		"panic retried": {
+			fn: func(ctx context.Context, j Job) error { panic("nil map") },
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(`SET "state" = 'pending', "run_at" = $2`)).
+					WithArgs("1", sqlmock.AnyArg(), "panic: nil map").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		"canceled by the shutdown released": {
+			fn: func(ctx context.Context, j Job) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			shutdown: true,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(`SET "state" = 'pending', "attempts" = "attempts" - 1`)).
+					WithArgs("1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			p, mock := newTestProcessor(t)
+			tc.mockFn(mock)
+
+			if tc.shutdown {
+				// The runs overran the deadline of Close
+				p.cancelJobs()
+			}
+
+			// When
+			p.process(Job{ID: "1", Kind: "send welcome email", Attempt: 1, MaxAttempts: 3}, handler{fn: tc.fn})
+
+			// Then
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestProcessor_Replay(t *testing.T) {
+	tcs := map[string]struct {
+		mockFn func(mock sqlmock.Sqlmock)
+		expErr error
+	}{
+		"replayed": {
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(`SET "state" = 'pending', "attempts" = 0`)).
+					WithArgs("1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		"not dead": {
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(`SET "state" = 'pending', "attempts" = 0`)).
+					WithArgs("1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expErr: ErrNotFound,
+		},
+		"unique key enqueued": {
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(`SET "state" = 'pending', "attempts" = 0`)).
+					WithArgs("1").
+					WillReturnError(&pgconn.PgError{Code: uniqueViolation})
+			},
+			expErr: ErrDuplicated,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			p, mock := newTestProcessor(t)
+			tc.mockFn(mock)
+
+			// When
+			err := p.Replay(context.Background(), "1")
+
+			// Then
+			require.NoError(t, mock.ExpectationsWereMet())
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestProcessor_Run(t *testing.T) {
+	// Given
+	p, mock := newTestProcessor(t, Concurrency(2), PollInterval(time.Hour))
+	mock.MatchExpectationsInOrder(false)
+
+	var running, maxRunning atomic.Int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	p.Handle("send welcome email", func(ctx context.Context, j Job) error {
+		defer wg.Done()
+
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			max := maxRunning.Load()
+			if n <= max || maxRunning.CompareAndSwap(max, n) {
+				break
+			}
+		}
+
+		<-release
+		return nil
+	})
+
+	// The jobs claimed by the other processors are skipped, as many jobs as the free workers are claimed
+	mock.ExpectQuery(regexp.QuoteMeta(`FOR UPDATE SKIP LOCKED`)).
+		WithArgs([]string{"send welcome email"}, 2, defaultLease.Seconds()).
+		WillReturnRows(sqlmock.NewRows(claimedColumns).
+			AddRow("1", "send welcome email", []byte(`{}`), []byte(`{}`), StateRunning, 1, 3, "", time.Now()).
+			AddRow("2", "send welcome email", []byte(`{}`), []byte(`{}`), StateRunning, 1, 3, "", time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta(`SET "state" = 'completed'`)).WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`SET "state" = 'completed'`)).WithArgs("2").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	// When
+	go func() { done <- p.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return running.Load() == 2 }, time.Second, time.Millisecond)
+
+	// Then
+	// Every worker is busy, no job is claimed until one is free
+	acquireCtx, acquireCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer acquireCancel()
+	require.Equal(t, 0, p.acquire(acquireCtx))
+
+	close(release)
+	wg.Wait()
+	cancel()
+	require.NoError(t, <-done)
+	require.NoError(t, p.Close(context.Background()))
+
+	require.Equal(t, int32(2), maxRunning.Load())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProcessor_RunClosed(t *testing.T) {
+	// Given
+	p := NewProcessor(nil, PollInterval(time.Millisecond))
+	p.Handle("send welcome email", func(ctx context.Context, j Job) error { return nil })
+	require.NoError(t, p.Close(context.Background()))
+
+	done := make(chan error)
+
+	// When
+	go func() { done <- p.Run(context.Background()) }()
+
+	// Then
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("run does not return once closed")
+	}
+}
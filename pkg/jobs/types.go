@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// States of a job
+const (
+	StatePending   = "pending"
+	StateRunning   = "running"
+	StateCompleted = "completed"
+	StateDead      = "dead"
+)
+
+// Handler handles a job, the job is retried with a backoff when it returns an error until its attempts
+// are exhausted, it is then dead until replayed
+type Handler func(ctx context.Context, job Job) error
+
+// Queue enqueues the jobs and registers their handlers
+type Queue interface {
+	// Enqueue the job of the kind with the args encoded in JSON, the job is written within the
+	// transaction bound to ctx when there is one, so that it is only run once the transaction is committed
+	Enqueue(ctx context.Context, kind string, args any, opts ...EnqueueOption) (string, error)
+
+	// Handle registers the handler of the jobs of the kind, it panics when the kind is already handled
+	Handle(kind string, fn Handler, opts ...HandlerOption)
+}
+
+// Job representing a job stored in the queue, the args are decoded into the type of its kind by the handlers
+type Job struct {
+	ID          string          `json:"id"`
+	Kind        string          `json:"kind"`
+	Args        json.RawMessage `json:"args"`
+	State       string          `json:"state"`
+	Attempt     int             `json:"attempt"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+
+	// traceContext the trace context of the enqueuer, the run span is linked to it
+	traceContext map[string]string
+}
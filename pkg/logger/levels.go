@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RootName the name of the root logger in the levels, setting its level sets the level of every logger
+const RootName = "root"
+
+var ErrUnknownLogger = errors.New("logger is not registered")
+
+// LevelStatus representing the level of a named logger
+type LevelStatus struct {
+	Name string `json:"name"`
+	// Level the current level of the logger
+	Level string `json:"level"`
+	// Base the level the logger reverts to once the temporary level expires
+	Base string `json:"base"`
+	// RevertAt the time the temporary level expires, nil when the level is not temporary
+	RevertAt *time.Time `json:"revertAt,omitempty"`
+}
+
+// Levels the registry of the levels of the named loggers, the levels can be changed at runtime,
+// permanently or temporarily
+type Levels struct {
+	mu      sync.Mutex
+	initial zapcore.Level
+	entries map[string]*levelEntry
+}
+
+type levelEntry struct {
+	level    zap.AtomicLevel
+	base     zapcore.Level
+	timer    *time.Timer
+	revertAt time.Time
+}
+
+// NewLevels creates the levels registry, the loggers are created at the initial level
+func NewLevels(initial string) (*Levels, error) {
+	lvl, err := zapcore.ParseLevel(initial)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Levels{
+		initial: lvl,
+		entries: make(map[string]*levelEntry),
+	}, nil
+}
+
+// Set sets the level of the logger and of its children, e.g. "users" sets the level of "users" and
+// "users.repository". The level is reverted once the ttl elapses when it is positive.
+func (l *Levels) Set(name, level string, ttl time.Duration) error {
+	lvl, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, exists := l.entries[name]; !exists {
+		return fmt.Errorf("%w: `%s`", ErrUnknownLogger, name)
+	}
+
+	for entryName, e := range l.entries {
+		if !matches(name, entryName) {
+			continue
+		}
+
+		if e.timer != nil {
+			e.timer.Stop()
+			e.timer, e.revertAt = nil, time.Time{}
+		}
+
+		e.level.SetLevel(lvl)
+
+		if ttl <= 0 {
+			e.base = lvl
+			continue
+		}
+
+		e := e
+		var timer *time.Timer
+		timer = time.AfterFunc(ttl, func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			// A timer firing while the level is set again is stale, it must not revert the newer level
+			if e.timer != timer {
+				return
+			}
+
+			e.level.SetLevel(e.base)
+			e.timer, e.revertAt = nil, time.Time{}
+		})
+		e.timer, e.revertAt = timer, time.Now().Add(ttl)
+	}
+
+	return nil
+}
+
+// Reset reverts the temporary levels of every logger to their base level
+func (l *Levels) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, e := range l.entries {
+		if e.timer != nil {
+			e.timer.Stop()
+			e.timer, e.revertAt = nil, time.Time{}
+		}
+
+		e.level.SetLevel(e.base)
+	}
+}
+
+// List returns the levels of the loggers sorted by name
+func (l *Levels) List() []LevelStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rs := make([]LevelStatus, 0, len(l.entries))
+	for name, e := range l.entries {
+		s := LevelStatus{
+			Name:  name,
+			Level: e.level.Level().String(),
+			Base:  e.base.String(),
+		}
+		if e.timer != nil {
+			revertAt := e.revertAt
+			s.RevertAt = &revertAt
+		}
+
+		rs = append(rs, s)
+	}
+
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Name < rs[j].Name })
+
+	return rs
+}
+
+// register returns the level of the named logger, a new logger starts at the base level of its parent
+func (l *Levels) register(name, parent string) zap.AtomicLevel {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e, exists := l.entries[name]; exists {
+		return e.level
+	}
+
+	base := l.initial
+	if p, exists := l.entries[parent]; exists {
+		base = p.base
+	}
+
+	e := &levelEntry{level: zap.NewAtomicLevelAt(base), base: base}
+	l.entries[name] = e
+
+	return e.level
+}
+
+// matches reports whether the logger is the named logger or one of its children
+func matches(name, entryName string) bool {
+	return name == RootName || entryName == name || strings.HasPrefix(entryName, name+".")
+}
+
+// leveledCore filters the entries of a core with the level of its logger, the core of the root logger
+// enables every level so that a child logger can be more verbose than its parent
+type leveledCore struct {
+	zapcore.Core
+	level zap.AtomicLevel
+}
+
+func (c leveledCore) Enabled(lvl zapcore.Level) bool {
+	return c.level.Enabled(lvl)
+}
+
+func (c leveledCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(e.Level) {
+		return ce
+	}
+
+	return c.Core.Check(e, ce)
+}
+
+func (c leveledCore) With(fields []zapcore.Field) zapcore.Core {
+	return leveledCore{Core: c.Core.With(fields), level: c.level}
+}
+
+// withLevel replaces the level of the core of the logger
+func withLevel(zl *zap.Logger, level zap.AtomicLevel) *zap.Logger {
+	return zl.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if lc, ok := core.(leveledCore); ok {
+			core = lc.Core
+		}
+
+		return leveledCore{Core: core, level: level}
+	}))
+}
@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newTestLogger(t *testing.T, level string) (Logger, *Levels, *bytes.Buffer) {
+	levels, err := NewLevels(level)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(newEncoderConfig()), zapcore.AddSync(buf), zap.DebugLevel)
+
+	return &structuredLogger{
+		zap:    withLevel(zap.New(core), levels.register(RootName, "")),
+		name:   RootName,
+		levels: levels,
+	}, levels, buf
+}
+
+func TestLevels_Set(t *testing.T) {
+	tcs := map[string]struct {
+		name     string
+		level    string
+		expLevel map[string]string
+		expErr   error
+	}{
+		"child only": {
+			name:     "users.repository",
+			level:    "debug",
+			expLevel: map[string]string{RootName: "info", "users": "info", "users.repository": "debug"},
+		},
+		"logger and its children": {
+			name:     "users",
+			level:    "error",
+			expLevel: map[string]string{RootName: "info", "users": "error", "users.repository": "error"},
+		},
+		"every logger": {
+			name:     RootName,
+			level:    "debug",
+			expLevel: map[string]string{RootName: "debug", "users": "debug", "users.repository": "debug"},
+		},
+		"unknown logger": {
+			name:   "orders",
+			level:  "debug",
+			expErr: ErrUnknownLogger,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			l, levels, _ := newTestLogger(t, "info")
+			l.Named("users").Named("repository")
+
+			// When
+			err := levels.Set(tc.name, tc.level, 0)
+
+			// Then
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+
+			require.NoError(t, err)
+			got := map[string]string{}
+			for _, s := range levels.List() {
+				got[s.Name] = s.Level
+			}
+			require.Equal(t, tc.expLevel, got)
+		})
+	}
+}
+
+func TestLevels_SetTTL(t *testing.T) {
+	// Given
+	l, levels, buf := newTestLogger(t, "info")
+	users := l.Named("users")
+
+	// When
+	require.NoError(t, levels.Set("users", "debug", 50*time.Millisecond))
+	users.Debugf("before revert")
+	l.Debugf("root is not changed")
+
+	// Then
+	require.Eventually(t, func() bool {
+		return levels.List()[1].Level == "info"
+	}, time.Second, 10*time.Millisecond)
+	users.Debugf("after revert")
+
+	require.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	require.Contains(t, buf.String(), "before revert")
+}
+
+func TestLevels_SetReplacesTTL(t *testing.T) {
+	// Given
+	_, levels, _ := newTestLogger(t, "info")
+	require.NoError(t, levels.Set("root", "debug", 10*time.Millisecond))
+
+	// When
+	require.NoError(t, levels.Set("root", "warn", 0))
+	time.Sleep(30 * time.Millisecond)
+
+	// Then
+	status := levels.List()[0]
+	require.Equal(t, "warn", status.Level)
+	require.Equal(t, "warn", status.Base)
+	require.Nil(t, status.RevertAt)
+}
@@ -10,31 +10,66 @@ import (
 
 type Config struct {
 	Environment string
+	// Levels the levels of the named loggers, a registry at the default level of the environment is
+	// created when nil
+	Levels *Levels
+	// Sampling the sampling of the entries, the entries are not sampled when nil
+	Sampling *Sampling
+}
+
+// Sampling the sampling of the entries logged with the same level and message each second, the first
+// Initial entries are logged then every Thereafter entry
+type Sampling struct {
+	Initial    int
+	Thereafter int
 }
 
 type structuredLogger struct {
-	zap *zap.Logger
+	zap    *zap.Logger
+	name   string
+	levels *Levels
+}
+
+// DefaultLevel returns the level of the loggers of the environment, debug outside the production
+func DefaultLevel(environment string) string {
+	if environment == "PRODUCTION" {
+		return zap.InfoLevel.String()
+	}
+
+	return zap.DebugLevel.String()
 }
 
 func New(cfg Config) (Logger, error) {
+	levels := cfg.Levels
+	if levels == nil {
+		var err error
+		if levels, err = NewLevels(DefaultLevel(cfg.Environment)); err != nil {
+			return nil, err
+		}
+	}
+
 	var zapLogger *zap.Logger
 	var err error
 
 	switch cfg.Environment {
 	case "PRODUCTION":
-		zapLogger, err = newZapConfig(withProductionConfig()).Build()
+		zapLogger, err = newZapConfig(withProductionConfig(), withSampling(cfg.Sampling)).Build()
 	default:
-		zapLogger, err = newZapConfig().Build()
+		zapLogger, err = newZapConfig(withSampling(cfg.Sampling)).Build()
 	}
 
 	if err != nil {
 		return &structuredLogger{
-			zap: zap.NewNop(),
+			zap:    zap.NewNop(),
+			name:   RootName,
+			levels: levels,
 		}, nil
 	}
 
 	return &structuredLogger{
-		zap: zapLogger,
+		zap:    withLevel(zapLogger, levels.register(RootName, "")),
+		name:   RootName,
+		levels: levels,
 	}, nil
 }
 
@@ -67,7 +102,22 @@ func (l structuredLogger) With(fields ...Field) Logger {
 	}
 
 	return &structuredLogger{
-		zap: l.zap.With(zapFields...),
+		zap:    l.zap.With(zapFields...),
+		name:   l.name,
+		levels: l.levels,
+	}
+}
+
+func (l structuredLogger) Named(name string) Logger {
+	fullName := name
+	if l.name != RootName {
+		fullName = l.name + "." + name
+	}
+
+	return &structuredLogger{
+		zap:    withLevel(l.zap.Named(name), l.levels.register(fullName, l.name)),
+		name:   fullName,
+		levels: l.levels,
 	}
 }
 
@@ -86,6 +136,8 @@ func (l structuredLogger) clone() Logger {
 	zapCloned := *l.zap // Copy value of l.zap
 
 	return structuredLogger{
-		zap: &zapCloned,
+		zap:    &zapCloned,
+		name:   l.name,
+		levels: l.levels,
 	}
 }
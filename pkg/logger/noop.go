@@ -26,6 +26,10 @@ func (n noopLogger) With(fields ...Field) Logger {
 	return n
 }
 
+func (n noopLogger) Named(name string) Logger {
+	return n
+}
+
 func (n noopLogger) Flush() error {
 	return nil
 }
@@ -0,0 +1,39 @@
+//go:build !windows
+
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// WatchSignals sets every logger to the debug level for the ttl on SIGUSR1, and reverts the temporary
+// levels on SIGUSR2, until ctx is done
+//
+//	kill -USR1 $(pidof serverd)
+func (l *Levels) WatchSignals(ctx context.Context, ttl time.Duration) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case sig := <-signals:
+			switch sig {
+			case syscall.SIGUSR1:
+				if err := l.Set(RootName, zap.DebugLevel.String(), ttl); err != nil {
+					return err
+				}
+			case syscall.SIGUSR2:
+				l.Reset()
+			}
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package logger
+
+import (
+	"context"
+	"time"
+)
+
+// WatchSignals waits until ctx is done, the levels cannot be changed with signals on windows
+func (l *Levels) WatchSignals(ctx context.Context, ttl time.Duration) error {
+	<-ctx.Done()
+
+	return nil
+}
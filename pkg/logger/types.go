@@ -16,6 +16,9 @@ type Logger interface {
 
 	// With creates a new structuredLogger instance with additional fields.
 	With(fields ...Field) Logger
+	// Named creates a child logger named after its parent, e.g. "users.repository", whose level is
+	// set independently of its parent at runtime.
+	Named(name string) Logger
 
 	// Flush flushing any buffered log entries
 	Flush() error
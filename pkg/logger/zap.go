@@ -8,11 +8,8 @@ import (
 func newZapConfig(opts ...zapOption) zap.Config {
 	cfg := zap.Config{
 		Development: true,
-		Level:       zap.NewAtomicLevelAt(zap.DebugLevel),
-		Sampling: &zap.SamplingConfig{
-			Initial:    100,
-			Thereafter: 100,
-		},
+		// The entries are filtered by the levels of the named loggers
+		Level:             zap.NewAtomicLevelAt(zap.DebugLevel),
 		Encoding:          "json",
 		EncoderConfig:     newEncoderConfig(),
 		OutputPaths:       []string{"stderr"},
@@ -33,7 +30,20 @@ type zapOption func(cfg *zap.Config)
 func withProductionConfig() zapOption {
 	return func(cfg *zap.Config) {
 		cfg.Development = false
-		cfg.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
+	}
+}
+
+func withSampling(s *Sampling) zapOption {
+	return func(cfg *zap.Config) {
+		if s == nil {
+			cfg.Sampling = nil
+			return
+		}
+
+		cfg.Sampling = &zap.SamplingConfig{
+			Initial:    s.Initial,
+			Thereafter: s.Thereafter,
+		}
 	}
 }
 
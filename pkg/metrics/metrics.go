@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// SecondsBuckets the histogram buckets of the durations in seconds, from 5ms to 10s
+var SecondsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Counter a monotonic counter, e.g. the number of users created
+type Counter interface {
+	Add(ctx context.Context, n int64, attrs ...attribute.KeyValue)
+}
+
+// UpDownCounter a counter which can be decreased, e.g. the number of in-flight requests
+type UpDownCounter interface {
+	Add(ctx context.Context, n int64, attrs ...attribute.KeyValue)
+}
+
+// Histogram a distribution of values, e.g. the durations of the requests
+type Histogram interface {
+	Record(ctx context.Context, v float64, attrs ...attribute.KeyValue)
+}
+
+// Meter creates the instruments of an instrumentation scope, e.g. a module, from the global meter
+// provider. The instruments created before the meter provider is set are forwarded to it once set.
+//
+//	var usersCreated = metrics.NewMeter("users").Counter("users.created", "Number of users created")
+//
+//	usersCreated.Add(ctx, 1)
+type Meter struct {
+	meter metric.Meter
+}
+
+// NewMeter returns the meter of the instrumentation scope
+func NewMeter(scope string) Meter {
+	return Meter{meter: otel.Meter(scope)}
+}
+
+// Counter creates a counter, the instrument does nothing when it cannot be created and the error
+// is handled by the global OpenTelemetry error handler
+func (m Meter) Counter(name, desc string) Counter {
+	c, err := m.meter.Int64Counter(name, metric.WithDescription(desc))
+	if err != nil {
+		otel.Handle(err)
+		c, _ = noop.NewMeterProvider().Meter("").Int64Counter(name)
+	}
+
+	return int64Counter{c: c}
+}
+
+// UpDownCounter creates an up down counter, see Counter for the errors
+func (m Meter) UpDownCounter(name, desc string) UpDownCounter {
+	c, err := m.meter.Int64UpDownCounter(name, metric.WithDescription(desc))
+	if err != nil {
+		otel.Handle(err)
+		c, _ = noop.NewMeterProvider().Meter("").Int64UpDownCounter(name)
+	}
+
+	return int64UpDownCounter{c: c}
+}
+
+// Histogram creates a histogram of the values in the unit, e.g. "s" for the durations in seconds
+// which are aggregated in SecondsBuckets, see Counter for the errors
+func (m Meter) Histogram(name, desc, unit string) Histogram {
+	h, err := m.meter.Float64Histogram(name, metric.WithDescription(desc), metric.WithUnit(unit))
+	if err != nil {
+		otel.Handle(err)
+		h, _ = noop.NewMeterProvider().Meter("").Float64Histogram(name)
+	}
+
+	return float64Histogram{h: h}
+}
+
+// Gauge creates a gauge observing the value returned by fn on each collection, e.g. the size of a
+// queue, see Counter for the errors
+func (m Meter) Gauge(name, desc string, fn func(ctx context.Context) (float64, []attribute.KeyValue)) {
+	_, err := m.meter.Float64ObservableGauge(name,
+		metric.WithDescription(desc),
+		metric.WithFloat64Callback(func(ctx context.Context, o metric.Float64Observer) error {
+			v, attrs := fn(ctx)
+			o.Observe(v, metric.WithAttributes(attrs...))
+			return nil
+		}),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+}
+
+type int64Counter struct {
+	c metric.Int64Counter
+}
+
+func (c int64Counter) Add(ctx context.Context, n int64, attrs ...attribute.KeyValue) {
+	c.c.Add(ctx, n, metric.WithAttributes(attrs...))
+}
+
+type int64UpDownCounter struct {
+	c metric.Int64UpDownCounter
+}
+
+func (c int64UpDownCounter) Add(ctx context.Context, n int64, attrs ...attribute.KeyValue) {
+	c.c.Add(ctx, n, metric.WithAttributes(attrs...))
+}
+
+type float64Histogram struct {
+	h metric.Float64Histogram
+}
+
+func (h float64Histogram) Record(ctx context.Context, v float64, attrs ...attribute.KeyValue) {
+	h.h.Record(ctx, v, metric.WithAttributes(attrs...))
+}
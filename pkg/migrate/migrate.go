@@ -0,0 +1,386 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"time"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/postgres"
+)
+
+var (
+	ErrInvalidSource   = errors.New("migrations are invalid")
+	ErrDirty           = errors.New("database is dirty, fix the failed migration then force its version")
+	ErrVersionNotFound = errors.New("migration version not found")
+	ErrIrreversible    = errors.New("migration has no down migration")
+)
+
+const (
+	// lockName the name of the advisory lock held while migrating, so that the runners migrate one at once
+	lockName = "migrate"
+
+	defaultLockRetry = time.Second
+)
+
+// Status representing the migration state of the database, the version is 0 when no migration is applied
+type Status struct {
+	Version    uint64
+	Dirty      bool
+	Migrations []MigrationStatus
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Migrator applies the migrations of a source to the database. The version of the database is stored in
+// the schema_migrations table, as the migrate CLI does, so that the databases migrated by either are
+// migrated by the other.
+//
+// A migration runs with the version update in a transaction on the session holding the lock of the
+// migrations, a failing migration is rolled back. A migration marked with a first line
+// `-- migrate:no-transaction`, e.g. creating an index concurrently, runs outside of a transaction and a
+// failure leaves the database dirty at its version until it is forced.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	fsys       fs.FS
+	logger     logger.Logger
+	lockRetry  time.Duration
+}
+
+// New creates a migrator of the migrations at the root of fsys, named <version>_<title>.up.sql and
+// <version>_<title>.down.sql
+//
+//	m, err := migrate.New(db, data.Migrations(), migrate.WithLogger(log))
+func New(db *sql.DB, fsys fs.FS, opts ...Option) (*Migrator, error) {
+	ms, err := readSource(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Migrator{
+		db:         db,
+		migrations: ms,
+		fsys:       fsys,
+		logger:     logger.NewNoop(),
+		lockRetry:  defaultLockRetry,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m, nil
+}
+
+// Up applies the next n migrations, every pending migration when n is 0
+func (m *Migrator) Up(ctx context.Context, n int) error {
+	return m.locked(ctx, func(ctx context.Context, conn *sql.Conn, version uint64) error {
+		return m.up(ctx, conn, version, math.MaxUint64, n)
+	})
+}
+
+// Down reverts the last n applied migrations, every applied migration when n is 0
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	return m.locked(ctx, func(ctx context.Context, conn *sql.Conn, version uint64) error {
+		return m.down(ctx, conn, version, 0, n)
+	})
+}
+
+// Goto applies or reverts the migrations until the version, 0 reverts every migration
+func (m *Migrator) Goto(ctx context.Context, version uint64) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrVersionNotFound, version)
+	}
+
+	return m.locked(ctx, func(ctx context.Context, conn *sql.Conn, current uint64) error {
+		if version >= current {
+			return m.up(ctx, conn, current, version, 0)
+		}
+
+		return m.down(ctx, conn, current, version, 0)
+	})
+}
+
+// Force sets the version of the database without migrating, e.g. once a failed migration is fixed by
+// hand, 0 sets that no migration is applied
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrVersionNotFound, version)
+	}
+
+	return postgres.WithLockConn(ctx, m.db, lockName, func(ctx context.Context, conn *sql.Conn) error {
+		if err := ensureTable(ctx, conn); err != nil {
+			return err
+		}
+
+		if err := inTx(ctx, conn, func(tx *sql.Tx) error {
+			return setVersion(ctx, tx, version, false)
+		}); err != nil {
+			return err
+		}
+
+		m.logger.Infof("version forced to %d", version)
+
+		return nil
+	})
+}
+
+// Status returns the version of the database and whether each migration is applied
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	if err := ensureTable(ctx, m.db); err != nil {
+		return Status{}, err
+	}
+
+	version, dirty, err := readVersion(ctx, m.db)
+	if err != nil {
+		return Status{}, err
+	}
+
+	s := Status{Version: version, Dirty: dirty}
+	for _, mg := range m.migrations {
+		s.Migrations = append(s.Migrations, MigrationStatus{
+			Migration: mg,
+			Applied:   mg.Version < version || (mg.Version == version && !dirty),
+		})
+	}
+
+	return s, nil
+}
+
+// locked runs fn with the session holding the lock of the migrations and the version of the database,
+// waiting for the other runners to release the lock. It fails when the database is dirty.
+func (m *Migrator) locked(ctx context.Context, fn func(ctx context.Context, conn *sql.Conn, version uint64) error) error {
+	for waiting := false; ; waiting = true {
+		err := postgres.WithLockConn(ctx, m.db, lockName, func(ctx context.Context, conn *sql.Conn) error {
+			if err := ensureTable(ctx, conn); err != nil {
+				return err
+			}
+
+			version, dirty, err := readVersion(ctx, conn)
+			if err != nil {
+				return err
+			}
+
+			if dirty {
+				return fmt.Errorf("%w: version %d", ErrDirty, version)
+			}
+
+			return fn(ctx, conn, version)
+		})
+		if !errors.Is(err, postgres.ErrLockNotAcquired) {
+			return err
+		}
+
+		if !waiting {
+			m.logger.Infof("waiting for another runner to complete the migrations")
+		}
+
+		timer := time.NewTimer(m.lockRetry)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// up applies up to n migrations after the version until the target, every one when n is 0
+func (m *Migrator) up(ctx context.Context, conn *sql.Conn, version, target uint64, n int) error {
+	applied := 0
+	for _, mg := range m.migrations {
+		if mg.Version <= version || mg.Version > target {
+			continue
+		}
+		if n > 0 && applied == n {
+			break
+		}
+
+		if err := m.run(ctx, conn, mg, mg.Up, mg.Version); err != nil {
+			return err
+		}
+		applied++
+	}
+
+	if applied == 0 {
+		m.logger.Infof("no migration to apply, the version is %d", version)
+	}
+
+	return nil
+}
+
+// down reverts up to n migrations from the version until the target, every one when n is 0
+func (m *Migrator) down(ctx context.Context, conn *sql.Conn, version, target uint64, n int) error {
+	if version == 0 {
+		m.logger.Infof("no migration to revert")
+		return nil
+	}
+
+	idx := m.index(version)
+	if idx < 0 {
+		return fmt.Errorf("%w: %d is applied to the database but is not in the migrations", ErrVersionNotFound, version)
+	}
+
+	for reverted := 0; idx >= 0 && m.migrations[idx].Version > target; idx-- {
+		if n > 0 && reverted == n {
+			break
+		}
+
+		mg := m.migrations[idx]
+		if mg.Down == "" {
+			return fmt.Errorf("%w: %d_%s", ErrIrreversible, mg.Version, mg.Name)
+		}
+
+		var previous uint64
+		if idx > 0 {
+			previous = m.migrations[idx-1].Version
+		}
+
+		if err := m.run(ctx, conn, mg, mg.Down, previous); err != nil {
+			return err
+		}
+		reverted++
+	}
+
+	return nil
+}
+
+// run executes the file of the migration then sets the version within a transaction, a migration
+// running outside of a transaction leaves the database dirty at the version while the file is executed
+// so that a failed migration is not applied again
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, mg Migration, file string, version uint64) error {
+	body, err := fs.ReadFile(m.fsys, file)
+	if err != nil {
+		return fmt.Errorf("read migration `%s`: %w", file, err)
+	}
+
+	started := time.Now()
+	if noTransaction(body) {
+		err = m.runNoTx(ctx, conn, string(body), version)
+	} else {
+		err = inTx(ctx, conn, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, string(body)); err != nil {
+				return err
+			}
+
+			return setVersion(ctx, tx, version, false)
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("migration `%s` failed: %w", file, err)
+	}
+
+	m.logger.Infof("migration `%s` of version %d applied in %s", file, mg.Version, time.Since(started))
+
+	return nil
+}
+
+// runNoTx executes the body outside of a transaction, the database is dirty at the version until the
+// body succeeds
+func (m *Migrator) runNoTx(ctx context.Context, conn *sql.Conn, body string, version uint64) error {
+	if err := inTx(ctx, conn, func(tx *sql.Tx) error {
+		return setVersion(ctx, tx, version, true)
+	}); err != nil {
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, body); err != nil {
+		return err
+	}
+
+	return inTx(ctx, conn, func(tx *sql.Tx) error {
+		return setVersion(ctx, tx, version, false)
+	})
+}
+
+// executor runs the statements of the migrations, a pooled connection, the session holding the lock or
+// one of its transactions
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func ensureTable(ctx context.Context, exec executor) error {
+	if _, err := exec.ExecContext(ctx,
+		`CREATE TABLE IF NOT EXISTS "schema_migrations" ("version" BIGINT NOT NULL PRIMARY KEY, "dirty" BOOLEAN NOT NULL)`,
+	); err != nil {
+		return fmt.Errorf("create schema migrations table: %w", err)
+	}
+
+	return nil
+}
+
+func readVersion(ctx context.Context, exec executor) (uint64, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := exec.QueryRowContext(ctx, `SELECT "version", "dirty" FROM "schema_migrations" LIMIT 1`).Scan(&version, &dirty)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, false, nil
+	case err != nil:
+		return 0, false, fmt.Errorf("read schema version: %w", err)
+	case version < 0:
+		// The migrate CLI stores -1 when a revert of every migration failed
+		return 0, dirty, nil
+	default:
+		return uint64(version), dirty, nil
+	}
+}
+
+func setVersion(ctx context.Context, tx *sql.Tx, version uint64, dirty bool) error {
+	if _, err := tx.ExecContext(ctx, `TRUNCATE "schema_migrations"`); err != nil {
+		return fmt.Errorf("set schema version: %w", err)
+	}
+
+	// No row means that no migration is applied
+	if version == 0 && !dirty {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO "schema_migrations" ("version", "dirty") VALUES ($1, $2)`,
+		int64(version), dirty,
+	); err != nil {
+		return fmt.Errorf("set schema version: %w", err)
+	}
+
+	return nil
+}
+
+// inTx runs fn within a transaction of the session, the transaction is committed when fn succeeds
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	return fn(tx)
+}
+
+// index returns the index of the migration of the version, -1 when there is none
+func (m *Migrator) index(version uint64) int {
+	for idx, mg := range m.migrations {
+		if mg.Version == version {
+			return idx
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,24 @@
+package migrate
+
+import (
+	"time"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+)
+
+type Option func(m *Migrator)
+
+func WithLogger(log logger.Logger) Option {
+	return func(m *Migrator) {
+		m.logger = log
+	}
+}
+
+// LockRetry sets the interval the lock of the migrations is retried at while another runner holds it
+func LockRetry(d time.Duration) Option {
+	return func(m *Migrator) {
+		if d > 0 {
+			m.lockRetry = d
+		}
+	}
+}
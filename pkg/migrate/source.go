@@ -0,0 +1,90 @@
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migration representing a migration of the source, the files are read when the migration is applied
+type Migration struct {
+	Version uint64
+	Name    string
+	// Up the file applying the migration, Down the file reverting it, empty when there is none
+	Up, Down string
+}
+
+// fileName the names of the migration files, e.g. 00001_setup_users.up.sql
+var fileName = regexp.MustCompile(`^([0-9]+)_(.+)\.(up|down)\.sql$`)
+
+// noTransactionMarker the first line of a migration running outside of a transaction
+const noTransactionMarker = "-- migrate:no-transaction"
+
+// noTransaction reports whether the migration runs outside of a transaction, e.g. CREATE INDEX
+// CONCURRENTLY cannot run within a transaction
+func noTransaction(body []byte) bool {
+	line, _, _ := bytes.Cut(body, []byte("\n"))
+
+	return strings.TrimSpace(string(line)) == noTransactionMarker
+}
+
+// readSource returns the migrations of the files at the root of fsys sorted by version, the files not
+// named as migrations are ignored
+func readSource(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		parts := fileName.FindStringSubmatch(entry.Name())
+		if parts == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: `%s`: version is invalid", ErrInvalidSource, entry.Name())
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = m
+		}
+
+		if m.Name != parts[2] {
+			return nil, fmt.Errorf("%w: version %d is named both `%s` and `%s`", ErrInvalidSource, version, m.Name, parts[2])
+		}
+
+		switch parts[3] {
+		case "up":
+			m.Up = entry.Name()
+		case "down":
+			m.Down = entry.Name()
+		}
+	}
+
+	ms := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("%w: version %d has no up migration", ErrInvalidSource, m.Version)
+		}
+		ms = append(ms, *m)
+	}
+
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].Version < ms[j].Version
+	})
+
+	return ms, nil
+}
@@ -0,0 +1,100 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSource(t *testing.T) {
+	tcs := map[string]struct {
+		files  fstest.MapFS
+		expRs  []Migration
+		expErr error
+	}{
+		"sorted by version": {
+			files: fstest.MapFS{
+				"00002_setup_outbox.up.sql":   {},
+				"00002_setup_outbox.down.sql": {},
+				"00001_setup_users.up.sql":    {},
+				"00001_setup_users.down.sql":  {},
+				"00003_seed.up.sql":           {},
+				"README.md":                   {},
+				"migrations.go":               {},
+			},
+			expRs: []Migration{
+				{Version: 1, Name: "setup_users", Up: "00001_setup_users.up.sql", Down: "00001_setup_users.down.sql"},
+				{Version: 2, Name: "setup_outbox", Up: "00002_setup_outbox.up.sql", Down: "00002_setup_outbox.down.sql"},
+				{Version: 3, Name: "seed", Up: "00003_seed.up.sql"},
+			},
+		},
+		"empty": {
+			files: fstest.MapFS{},
+			expRs: []Migration{},
+		},
+		"up missing": {
+			files: fstest.MapFS{
+				"00001_setup_users.down.sql": {},
+			},
+			expErr: ErrInvalidSource,
+		},
+		"names differ": {
+			files: fstest.MapFS{
+				"00001_setup_users.up.sql":  {},
+				"00001_setup_user.down.sql": {},
+			},
+			expErr: ErrInvalidSource,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// When
+			rs, err := readSource(tc.files)
+
+			// Then
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expRs, rs)
+		})
+	}
+}
+
+func TestNoTransaction(t *testing.T) {
+	tcs := map[string]struct {
+		body  string
+		expRs bool
+	}{
+		"marked": {
+			body:  "-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY \"name_on_users\" ON \"users\"(\"name\");\n",
+			expRs: true,
+		},
+		"marked with trailing spaces": {
+			body:  "-- migrate:no-transaction  \r\nCREATE INDEX CONCURRENTLY \"name_on_users\" ON \"users\"(\"name\");\n",
+			expRs: true,
+		},
+		"not marked": {
+			body: "--\n-- USERS table\n--\nCREATE TABLE \"users\" (\"id\" BIGINT PRIMARY KEY);\n",
+		},
+		"marked after the first line": {
+			body: "CREATE TABLE \"users\" (\"id\" BIGINT PRIMARY KEY);\n-- migrate:no-transaction\n",
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// When
+			rs := noTransaction([]byte(tc.body))
+
+			// Then
+			require.Equal(t, tc.expRs, rs)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package otlp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/virsavik/alchemist-template/pkg/config"
+)
+
+// NewTraceExporter creates the OTLP exporter of the traces with the protocol, the TLS and the headers
+// of the configuration
+func NewTraceExporter(ctx context.Context, cfg config.OtelConfig) (sdktrace.SpanExporter, error) {
+	tlsCfg, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ExporterProtocol == config.OTLPProtocolHTTP {
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(cfg.ExporterEndpoint),
+			otlptracehttp.WithHeaders(cfg.ExporterHeaders),
+		}
+		if tlsCfg == nil {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		} else {
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsCfg))
+		}
+
+		return otlptracehttp.New(ctx, opts...)
+	}
+
+	opts := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(cfg.ExporterEndpoint),
+		otlptracegrpc.WithHeaders(cfg.ExporterHeaders),
+	}
+	if tlsCfg == nil {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	} else {
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+	}
+
+	return otlptracegrpc.New(ctx, opts...)
+}
+
+// NewMetricExporter creates the OTLP exporter of the metrics with the protocol, the TLS and the headers
+// of the configuration
+func NewMetricExporter(ctx context.Context, cfg config.OtelConfig) (sdkmetric.Exporter, error) {
+	tlsCfg, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ExporterProtocol == config.OTLPProtocolHTTP {
+		opts := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithEndpoint(cfg.ExporterEndpoint),
+			otlpmetrichttp.WithHeaders(cfg.ExporterHeaders),
+		}
+		if tlsCfg == nil {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		} else {
+			opts = append(opts, otlpmetrichttp.WithTLSClientConfig(tlsCfg))
+		}
+
+		return otlpmetrichttp.New(ctx, opts...)
+	}
+
+	opts := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithEndpoint(cfg.ExporterEndpoint),
+		otlpmetricgrpc.WithHeaders(cfg.ExporterHeaders),
+	}
+	if tlsCfg == nil {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	} else {
+		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+	}
+
+	return otlpmetricgrpc.New(ctx, opts...)
+}
+
+// newTLSConfig returns the TLS of the exporter, nil when it is insecure. The endpoint is verified with
+// the CA certificate of the configuration, or with the system CAs.
+func newTLSConfig(cfg config.OtelConfig) (*tls.Config, error) {
+	if cfg.ExporterInsecure {
+		return nil, nil
+	}
+
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.ExporterCertificate == "" {
+		return tlsCfg, nil
+	}
+
+	pem, err := os.ReadFile(cfg.ExporterCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("read exporter certificate: %w", err)
+	}
+
+	tlsCfg.RootCAs = x509.NewCertPool()
+	if !tlsCfg.RootCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("exporter certificate `%s` is invalid", cfg.ExporterCertificate)
+	}
+
+	return tlsCfg, nil
+}
@@ -0,0 +1,61 @@
+package otlp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/virsavik/alchemist-template/pkg/config"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	invalidCert := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(invalidCert, []byte("not a certificate"), 0o600))
+
+	tcs := map[string]struct {
+		cfg    config.OtelConfig
+		expNil bool
+		expErr bool
+	}{
+		"insecure": {
+			cfg:    config.OtelConfig{ExporterInsecure: true, ExporterCertificate: invalidCert},
+			expNil: true,
+		},
+		"system CAs": {
+			cfg: config.OtelConfig{},
+		},
+		"certificate not found": {
+			cfg:    config.OtelConfig{ExporterCertificate: filepath.Join(t.TempDir(), "missing.pem")},
+			expErr: true,
+		},
+		"certificate invalid": {
+			cfg:    config.OtelConfig{ExporterCertificate: invalidCert},
+			expErr: true,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// When
+			tlsCfg, err := newTLSConfig(tc.cfg)
+
+			// Then
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			if tc.expNil {
+				require.Nil(t, tlsCfg)
+				return
+			}
+
+			require.NotNil(t, tlsCfg)
+			require.Nil(t, tlsCfg.RootCAs)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+)
+
+type Option func(r *Relay)
+
+func WithLogger(log logger.Logger) Option {
+	return func(r *Relay) {
+		r.logger = log
+	}
+}
+
+// PollInterval sets the interval the outbox is polled at when there is no pending event
+func PollInterval(d time.Duration) Option {
+	return func(r *Relay) {
+		if d > 0 {
+			r.pollInterval = d
+		}
+	}
+}
+
+// BatchSize sets the max number of events published by a poll
+func BatchSize(n int) Option {
+	return func(r *Relay) {
+		if n > 0 {
+			r.batchSize = n
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/virsavik/alchemist-template/pkg/events"
+	"github.com/virsavik/alchemist-template/pkg/postgres"
+)
+
+// Write writes the events into the outbox table with the executor, the events are published by the
+// relay once the transaction of the executor is committed, so that the events are neither lost when
+// the application crashes after the commit nor published when the transaction is rolled back.
+//
+//	err := uow.WithinTx(ctx, func(ctx context.Context) error {
+//		tx := postgres.ExecutorFromCtx(ctx, db)
+//		// save the user with tx
+//		return outbox.Write(ctx, tx, events.New(ctx, UserCreatedTopic, UserCreated{User: user}))
+//	})
+func Write(ctx context.Context, exec postgres.ContextExecutor, es ...events.Envelope) error {
+	for _, e := range es {
+		payload, err := json.Marshal(e.Payload)
+		if err != nil {
+			return fmt.Errorf("marshal event `%s` payload: %w", e.Name, err)
+		}
+
+		traceContext, err := json.Marshal(e.TraceContext)
+		if err != nil {
+			return fmt.Errorf("marshal event `%s` trace context: %w", e.Name, err)
+		}
+
+		if _, err = exec.ExecContext(ctx,
+			`INSERT INTO "outbox" ("id", "name", "payload", "trace_context", "occurred_at") VALUES ($1, $2, $3, $4, $5)`,
+			e.ID, e.Name, payload, traceContext, e.OccurredAt,
+		); err != nil {
+			return fmt.Errorf("write event `%s` to the outbox: %w", e.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/virsavik/alchemist-template/pkg/events"
+	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/metrics"
+)
+
+const (
+	scopeName = "github.com/virsavik/alchemist-template/pkg/outbox"
+
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+)
+
+// Publisher publishes the events relayed from the outbox, e.g. the events Bus or a message broker
+type Publisher interface {
+	Publish(ctx context.Context, es ...events.Envelope) error
+}
+
+// Relay publishes the events written into the outbox, in the order they were written. The events are
+// marked dispatched once the publisher accepts them, an event failing to publish is retried by the next
+// poll, so that the events are published at least once.
+//
+// The delivery to the subscribers is guaranteed by the publisher only: an in-process publisher such as
+// the events Broker accepts the events once buffered, the events buffered but not yet handled when the
+// process crashes are lost, so that they are delivered at most once. The dispatched events are kept
+// until purged.
+//
+// The pending events are locked while being published, several relays share the outbox without
+// publishing the same event twice.
+type Relay struct {
+	db           *sql.DB
+	publisher    Publisher
+	logger       logger.Logger
+	pollInterval time.Duration
+	batchSize    int
+
+	pending atomic.Int64
+	// oldest the creation time in unix nanoseconds of the oldest pending event, 0 when there is none
+	oldest atomic.Int64
+
+	dispatched metrics.Counter
+	failed     metrics.Counter
+}
+
+// NewRelay creates a relay publishing the events of the outbox of the database
+func NewRelay(db *sql.DB, publisher Publisher, opts ...Option) *Relay {
+	r := &Relay{
+		db:           db,
+		publisher:    publisher,
+		logger:       logger.NewNoop(),
+		pollInterval: defaultPollInterval,
+		batchSize:    defaultBatchSize,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	meter := metrics.NewMeter(scopeName)
+	r.dispatched = meter.Counter("outbox.events.dispatched", "Number of events published from the outbox")
+	r.failed = meter.Counter("outbox.events.failed", "Number of events failing to publish from the outbox")
+	meter.Gauge("outbox.events.pending", "Number of events waiting in the outbox",
+		func(ctx context.Context) (float64, []attribute.KeyValue) {
+			return float64(r.pending.Load()), nil
+		})
+	meter.Gauge("outbox.lag", "Time in seconds the oldest pending event has been waiting in the outbox",
+		func(ctx context.Context) (float64, []attribute.KeyValue) {
+			oldest := r.oldest.Load()
+			if oldest == 0 {
+				return 0, nil
+			}
+			return time.Since(time.Unix(0, oldest)).Seconds(), nil
+		})
+
+	return r
+}
+
+// Run polls the outbox until ctx is done, a full batch is followed by the next one without waiting
+func (r *Relay) Run(ctx context.Context) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+
+		n, err := r.relay(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Errorf(err, "relay outbox events failed")
+		}
+
+		if err := r.refreshLag(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Errorf(err, "refresh outbox lag failed")
+		}
+
+		if n == r.batchSize && err == nil {
+			timer.Reset(0)
+		} else {
+			timer.Reset(r.pollInterval)
+		}
+	}
+}
+
+// relay publishes a batch of pending events within a transaction holding their locks, it returns the
+// number of events dispatched
+func (r *Relay) relay(ctx context.Context) (n int, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	es, err := r.lockPending(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	var dispatched []string
+	for _, e := range es {
+		// The publishing is linked to the trace of the writer of the event
+		pubCtx := otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(e.TraceContext))
+
+		if pubErr := r.publisher.Publish(pubCtx, e); pubErr != nil {
+			r.failed.Add(ctx, 1, attribute.String("event", e.Name))
+			r.logger.Warnf("publish outbox event `%s` (%s) failed: %v", e.Name, e.ID, pubErr)
+
+			if _, err = tx.ExecContext(ctx,
+				`UPDATE "outbox" SET "attempts" = "attempts" + 1, "last_error" = $2 WHERE "id" = $1`,
+				e.ID, pubErr.Error(),
+			); err != nil {
+				return 0, err
+			}
+
+			// The next events are not published before this one, so that the order is kept
+			break
+		}
+
+		dispatched = append(dispatched, e.ID)
+		r.dispatched.Add(ctx, 1, attribute.String("event", e.Name))
+	}
+
+	for _, id := range dispatched {
+		if _, err = tx.ExecContext(ctx,
+			`UPDATE "outbox" SET "dispatched_at" = NOW(), "attempts" = "attempts" + 1, "last_error" = NULL WHERE "id" = $1`,
+			id,
+		); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(dispatched), nil
+}
+
+// lockPending locks the oldest pending events, skipping the events locked by the other relays
+func (r *Relay) lockPending(ctx context.Context, tx *sql.Tx) ([]events.Envelope, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT "id", "name", "payload", "trace_context", "occurred_at" FROM "outbox"
+		WHERE "dispatched_at" IS NULL
+		ORDER BY "created_at"
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`,
+		r.batchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var es []events.Envelope
+	for rows.Next() {
+		var (
+			e            events.Envelope
+			payload      []byte
+			traceContext []byte
+		)
+		if err = rows.Scan(&e.ID, &e.Name, &payload, &traceContext, &e.OccurredAt); err != nil {
+			return nil, err
+		}
+
+		// The payload is decoded into the type of the topic by the subscribers
+		e.Payload = json.RawMessage(payload)
+		if err = json.Unmarshal(traceContext, &e.TraceContext); err != nil {
+			return nil, fmt.Errorf("unmarshal event `%s` trace context: %w", e.ID, err)
+		}
+
+		es = append(es, e)
+	}
+
+	return es, rows.Err()
+}
+
+// refreshLag refreshes the number of pending events and the creation time of the oldest one
+func (r *Relay) refreshLag(ctx context.Context) error {
+	var (
+		pending int64
+		oldest  sql.NullTime
+	)
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*), MIN("created_at") FROM "outbox" WHERE "dispatched_at" IS NULL`,
+	).Scan(&pending, &oldest)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	r.pending.Store(pending)
+	if oldest.Valid {
+		r.oldest.Store(oldest.Time.UnixNano())
+	} else {
+		r.oldest.Store(0)
+	}
+
+	return nil
+}
+
+// Purge deletes the events dispatched before the time, it returns the number of events deleted
+func (r *Relay) Purge(ctx context.Context, dispatchedBefore time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM "outbox" WHERE "dispatched_at" < $1`,
+		dispatchedBefore,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
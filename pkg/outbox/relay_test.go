@@ -0,0 +1,217 @@
+package outbox
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/virsavik/alchemist-template/pkg/events"
+)
+
+// fakePublisher records the events published, it fails the events whose id is in fail
+type fakePublisher struct {
+	published []string
+	fail      map[string]error
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, es ...events.Envelope) error {
+	for _, e := range es {
+		if err := p.fail[e.ID]; err != nil {
+			return err
+		}
+		p.published = append(p.published, e.ID)
+	}
+
+	return nil
+}
+
+// timeBetween matches a time within the bounds
+type timeBetween struct {
+	from, to time.Time
+}
+
+func (m timeBetween) Match(v driver.Value) bool {
+	t, ok := v.(time.Time)
+	return ok && !t.Before(m.from) && !t.After(m.to)
+}
+
+var pendingColumns = []string{"id", "name", "payload", "trace_context", "occurred_at"}
+
+func newTestRelay(t *testing.T, publisher Publisher) (*Relay, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return NewRelay(db, publisher, BatchSize(10)), mock
+}
+
+func expectPending(mock sqlmock.Sqlmock, ids ...string) {
+	rows := sqlmock.NewRows(pendingColumns)
+	for _, id := range ids {
+		rows.AddRow(id, "users.created", []byte(`{"id":1}`), []byte(`{}`), time.Now())
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FOR UPDATE SKIP LOCKED`)).WithArgs(10).WillReturnRows(rows)
+}
+
+func expectDispatched(mock sqlmock.Sqlmock, id string) {
+	mock.ExpectExec(regexp.QuoteMeta(`SET "dispatched_at" = NOW()`)).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestRelay_Relay(t *testing.T) {
+	tcs := map[string]struct {
+		fail         map[string]error
+		mockFn       func(mock sqlmock.Sqlmock)
+		expN         int
+		expPublished []string
+	}{
+		"dispatched": {
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectPending(mock, "1", "2")
+				expectDispatched(mock, "1")
+				expectDispatched(mock, "2")
+				mock.ExpectCommit()
+			},
+			expN:         2,
+			expPublished: []string{"1", "2"},
+		},
+		"no pending event": {
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectPending(mock)
+				mock.ExpectCommit()
+			},
+		},
+		"publish failed": {
+			fail: map[string]error{"2": errors.New("broker is closed")},
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectPending(mock, "1", "2", "3")
+				// The failed event stays pending, the next events are not published so that the order is kept
+				mock.ExpectExec(regexp.QuoteMeta(`SET "attempts" = "attempts" + 1, "last_error" = $2`)).
+					WithArgs("2", "broker is closed").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectDispatched(mock, "1")
+				mock.ExpectCommit()
+			},
+			expN:         1,
+			expPublished: []string{"1"},
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			publisher := &fakePublisher{fail: tc.fail}
+			r, mock := newTestRelay(t, publisher)
+			tc.mockFn(mock)
+
+			// When
+			n, err := r.relay(context.Background())
+
+			// Then
+			require.NoError(t, err)
+			require.NoError(t, mock.ExpectationsWereMet())
+			require.Equal(t, tc.expN, n)
+			require.Equal(t, tc.expPublished, publisher.published)
+		})
+	}
+}
+
+func TestRelay_RelayRetried(t *testing.T) {
+	// Given
+	publisher := &fakePublisher{fail: map[string]error{"1": errors.New("broker is closed")}}
+	r, mock := newTestRelay(t, publisher)
+
+	mock.ExpectBegin()
+	expectPending(mock, "1")
+	mock.ExpectExec(regexp.QuoteMeta(`SET "attempts" = "attempts" + 1, "last_error" = $2`)).
+		WithArgs("1", "broker is closed").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := r.relay(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// When
+	publisher.fail = nil
+	mock.ExpectBegin()
+	expectPending(mock, "1")
+	expectDispatched(mock, "1")
+	mock.ExpectCommit()
+
+	n, err = r.relay(context.Background())
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []string{"1"}, publisher.published)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRelay_RefreshLag(t *testing.T) {
+	oldest := time.Now().Add(-time.Minute)
+
+	tcs := map[string]struct {
+		rows       *sqlmock.Rows
+		expPending int64
+		expOldest  int64
+	}{
+		"pending events": {
+			rows:       sqlmock.NewRows([]string{"count", "min"}).AddRow(3, oldest),
+			expPending: 3,
+			expOldest:  oldest.UnixNano(),
+		},
+		"no pending event": {
+			rows: sqlmock.NewRows([]string{"count", "min"}).AddRow(0, nil),
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			r, mock := newTestRelay(t, &fakePublisher{})
+			r.pending.Store(10)
+			r.oldest.Store(1)
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*), MIN("created_at") FROM "outbox"`)).WillReturnRows(tc.rows)
+
+			// When
+			err := r.refreshLag(context.Background())
+
+			// Then
+			require.NoError(t, err)
+			require.NoError(t, mock.ExpectationsWereMet())
+			require.Equal(t, tc.expPending, r.pending.Load())
+			require.Equal(t, tc.expOldest, r.oldest.Load())
+		})
+	}
+}
+
+func TestRelay_Purge(t *testing.T) {
+	// Given
+	r, mock := newTestRelay(t, &fakePublisher{})
+	before := time.Now().Add(-7 * 24 * time.Hour)
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "outbox" WHERE "dispatched_at" < $1`)).
+		WithArgs(timeBetween{from: before, to: before}).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	// When
+	n, err := r.Purge(context.Background(), before)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, int64(42), n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
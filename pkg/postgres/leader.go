@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/waiter"
+)
+
+const defaultLeaderRetry = 5 * time.Second
+
+type election struct {
+	name      string
+	logger    logger.Logger
+	retry     time.Duration
+	heartbeat time.Duration
+	// tryLock acquires the leadership, the advisory lock of the name by default
+	tryLock func(ctx context.Context) (leaderLock, error)
+}
+
+// leaderLock the lock of the leadership, see Lock
+type leaderLock interface {
+	Lost() <-chan struct{}
+	Unlock(ctx context.Context) error
+}
+
+type LeaderOption func(e *election)
+
+func LeaderLogger(log logger.Logger) LeaderOption {
+	return func(e *election) {
+		e.logger = log
+	}
+}
+
+// LeaderRetry sets the interval the followers try to acquire the leadership at
+func LeaderRetry(d time.Duration) LeaderOption {
+	return func(e *election) {
+		if d > 0 {
+			e.retry = d
+		}
+	}
+}
+
+// LeaderHeartbeat sets the interval the leader checks it still holds the leadership at, see LockHeartbeat
+func LeaderHeartbeat(d time.Duration) LeaderOption {
+	return func(e *election) {
+		if d > 0 {
+			e.heartbeat = d
+		}
+	}
+}
+
+// Leader returns a wait function running fn only while the replica is the leader of the name, so that a
+// singleton task runs on a single replica at once. The leadership is the advisory lock of the name, the
+// followers try to acquire it until ctx is done.
+//
+// The context of fn is canceled once the leadership is lost, fn runs again once the leadership is
+// acquired back. The leadership is released when fn returns, its error is returned.
+//
+//	w.Add(postgres.Leader(db, "scheduler", s.Run), waiter.TaskName("scheduler"))
+func Leader(db *sql.DB, name string, fn waiter.WaitFunc, opts ...LeaderOption) waiter.WaitFunc {
+	e := election{
+		name:      name,
+		logger:    logger.NewNoop(),
+		retry:     defaultLeaderRetry,
+		heartbeat: defaultLockHeartbeat,
+	}
+
+	for _, opt := range opts {
+		opt(&e)
+	}
+
+	e.tryLock = func(ctx context.Context) (leaderLock, error) {
+		return TryLock(ctx, db, e.name, LockHeartbeat(e.heartbeat))
+	}
+
+	return func(ctx context.Context) error {
+		return e.run(ctx, fn)
+	}
+}
+
+// run runs fn while leading until ctx is done or fn returns
+func (e election) run(ctx context.Context, fn waiter.WaitFunc) error {
+	for {
+		lock, err := e.tryLock(ctx)
+		switch {
+		case err == nil:
+			e.logger.Infof("leadership of `%s` acquired", e.name)
+
+			lost, err := e.lead(ctx, lock, fn)
+			if !lost {
+				return err
+			}
+		case errors.Is(err, ErrLockNotAcquired):
+		case ctx.Err() != nil:
+			return nil
+		default:
+			e.logger.Warnf("acquire leadership of `%s` failed: %v", e.name, err)
+		}
+
+		timer := time.NewTimer(e.retry)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// lead runs fn until it returns or the leadership is lost, it reports whether the leadership is lost
+func (e election) lead(ctx context.Context, lock leaderLock, fn waiter.WaitFunc) (bool, error) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		// A panic of fn is returned as its error, so that the leadership is released and fn restarted
+		// by the waiter
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+
+		done <- fn(leaderCtx)
+	}()
+
+	select {
+	case err := <-done:
+		unlockCtx, cancel := context.WithTimeout(context.Background(), unlockTimeout)
+		defer cancel()
+
+		if unlockErr := lock.Unlock(unlockCtx); unlockErr != nil {
+			e.logger.Warnf("release leadership of `%s` failed: %v", e.name, unlockErr)
+		} else {
+			e.logger.Infof("leadership of `%s` released", e.name)
+		}
+
+		return false, err
+	case <-lock.Lost():
+		e.logger.Warnf("leadership of `%s` lost", e.name)
+
+		cancel()
+		if err := <-done; err != nil {
+			e.logger.Errorf(err, "`%s` failed on leadership loss", e.name)
+		}
+
+		return true, nil
+	}
+}
@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+)
+
+// fakeLeaderLock a leadership lost once the test closes lost
+type fakeLeaderLock struct {
+	lost     chan struct{}
+	unlocked atomic.Bool
+}
+
+func newFakeLeaderLock() *fakeLeaderLock {
+	return &fakeLeaderLock{lost: make(chan struct{})}
+}
+
+func (l *fakeLeaderLock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *fakeLeaderLock) Unlock(ctx context.Context) error {
+	l.unlocked.Store(true)
+	return nil
+}
+
+func newTestElection(tryLock func(ctx context.Context) (leaderLock, error)) election {
+	return election{
+		name:    "scheduler",
+		logger:  logger.NewNoop(),
+		retry:   time.Millisecond,
+		tryLock: tryLock,
+	}
+}
+
+func TestElection_Lead(t *testing.T) {
+	tcs := map[string]struct {
+		fn          func(ctx context.Context) error
+		lose        bool
+		expLost     bool
+		expErr      string
+		expUnlocked bool
+	}{
+		"fn returns": {
+			fn:          func(ctx context.Context) error { return nil },
+			expUnlocked: true,
+		},
+		"fn fails": {
+			fn:          func(ctx context.Context) error { return errors.New("relay failed") },
+			expErr:      "relay failed",
+			expUnlocked: true,
+		},
+		"fn panics": {
+			fn:          func(ctx context.Context) error { panic("nil map") },
+			expErr:      "panic: nil map",
+			expUnlocked: true,
+		},
+		"leadership lost": {
+			fn: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			lose:    true,
+			expLost: true,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			lock := newFakeLeaderLock()
+			if tc.lose {
+				close(lock.lost)
+			}
+			e := newTestElection(nil)
+
+			// When
+			lost, err := e.lead(context.Background(), lock, tc.fn)
+
+			// Then
+			require.Equal(t, tc.expLost, lost)
+			if tc.expErr != "" {
+				require.EqualError(t, err, tc.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expUnlocked, lock.unlocked.Load())
+		})
+	}
+}
+
+func TestElection_Run(t *testing.T) {
+	// Given
+	var (
+		mu       sync.Mutex
+		attempts int
+		locks    []*fakeLeaderLock
+	)
+	e := newTestElection(func(ctx context.Context) (leaderLock, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Another replica leads first
+		attempts++
+		if attempts < 3 {
+			return nil, ErrLockNotAcquired
+		}
+
+		lock := newFakeLeaderLock()
+		locks = append(locks, lock)
+		return lock, nil
+	})
+
+	var runs atomic.Int32
+	fn := func(ctx context.Context) error {
+		if runs.Add(1) == 1 {
+			// The first leadership is lost, fn is canceled and runs again once it is acquired back
+			mu.Lock()
+			close(locks[0].lost)
+			mu.Unlock()
+
+			<-ctx.Done()
+			return nil
+		}
+
+		return nil
+	}
+
+	// When
+	err := e.run(context.Background(), fn)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, int32(2), runs.Load())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, 4, attempts)
+	require.Len(t, locks, 2)
+	require.False(t, locks[0].unlocked.Load())
+	require.True(t, locks[1].unlocked.Load())
+}
+
+func TestElection_RunCanceled(t *testing.T) {
+	// Given
+	e := newTestElection(func(ctx context.Context) (leaderLock, error) {
+		return nil, ErrLockNotAcquired
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// When
+	err := e.run(ctx, func(ctx context.Context) error {
+		t.Fatal("fn runs without the leadership")
+		return nil
+	})
+
+	// Then
+	require.NoError(t, err)
+}
@@ -0,0 +1,281 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrLockNotAcquired = errors.New("advisory lock is held by another session")
+	ErrLockLost        = errors.New("advisory lock is lost")
+)
+
+const (
+	defaultLockHeartbeat = 5 * time.Second
+
+	// unlockTimeout bounds the release of a lock held until ctx is done
+	unlockTimeout = 5 * time.Second
+)
+
+// LockKey returns the key of the advisory lock of the name
+func LockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+
+	return int64(h.Sum64())
+}
+
+// Lock an advisory lock held by a session of the database. The lock is held as long as its session lives,
+// the session is checked at each heartbeat and the lock is lost once the session is, e.g. the connection
+// is broken, another session may hold the lock from then.
+type Lock struct {
+	name      string
+	key       int64
+	heartbeat time.Duration
+
+	// connMu guards the session, the heartbeat is skipped while the session is in use, see Conn
+	connMu  sync.Mutex
+	conn    *sql.Conn
+	session session
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	stopped  chan struct{}
+	lost     chan struct{}
+}
+
+type LockOption func(l *Lock)
+
+// LockHeartbeat sets the interval the session holding the lock is checked at
+func LockHeartbeat(d time.Duration) LockOption {
+	return func(l *Lock) {
+		if d > 0 {
+			l.heartbeat = d
+		}
+	}
+}
+
+// TryLock acquires the advisory lock of the name on a session dedicated to the lock, it returns
+// ErrLockNotAcquired without waiting when the lock is held by another session
+//
+//	lock, err := postgres.TryLock(ctx, db, "users.import")
+//	if err != nil {
+//		return err
+//	}
+//	defer lock.Unlock(ctx)
+func TryLock(ctx context.Context, db *sql.DB, name string, opts ...LockOption) (*Lock, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire advisory lock `%s`: %w", name, err)
+	}
+
+	key := LockKey(name)
+
+	var acquired bool
+	if err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired); err != nil {
+		discard(conn)
+		return nil, fmt.Errorf("acquire advisory lock `%s`: %w", name, err)
+	}
+
+	if !acquired {
+		_ = conn.Close()
+		return nil, fmt.Errorf("%w: `%s`", ErrLockNotAcquired, name)
+	}
+
+	return newLock(name, conn, connSession{Conn: conn}, opts...), nil
+}
+
+// newLock creates the lock of the name held by the session and checks the session until the lock is
+// released
+func newLock(name string, conn *sql.Conn, s session, opts ...LockOption) *Lock {
+	l := &Lock{
+		name:      name,
+		key:       LockKey(name),
+		conn:      conn,
+		session:   s,
+		heartbeat: defaultLockHeartbeat,
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+		lost:      make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	go l.keepAlive()
+
+	return l
+}
+
+// WithLock runs fn while holding the advisory lock of the name, it returns ErrLockNotAcquired without
+// running fn when the lock is held by another session, e.g. a cleanup run by a single replica
+func WithLock(ctx context.Context, db *sql.DB, name string, fn func(ctx context.Context) error) error {
+	return withLock(ctx, db, name, func(ctx context.Context, _ *Lock) error {
+		return fn(ctx)
+	})
+}
+
+// WithLockConn runs fn with the session holding the advisory lock of the name, e.g. to run a transaction
+// which must not run without the lock. The session is not checked while fn runs, a lost session fails
+// the statements of fn.
+func WithLockConn(ctx context.Context, db *sql.DB, name string, fn func(ctx context.Context, conn *sql.Conn) error) error {
+	return withLock(ctx, db, name, func(ctx context.Context, lock *Lock) error {
+		return lock.Conn(func(conn *sql.Conn) error {
+			return fn(ctx, conn)
+		})
+	})
+}
+
+func withLock(ctx context.Context, db *sql.DB, name string, fn func(ctx context.Context, lock *Lock) error) (err error) {
+	lock, err := TryLock(ctx, db, name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		unlockCtx, cancel := context.WithTimeout(context.Background(), unlockTimeout)
+		defer cancel()
+
+		if unlockErr := lock.Unlock(unlockCtx); unlockErr != nil && err == nil {
+			err = unlockErr
+		}
+	}()
+
+	// fn is canceled once the lock is lost
+	fnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-lock.Lost():
+			cancel()
+		case <-fnCtx.Done():
+		}
+	}()
+
+	return fn(fnCtx, lock)
+}
+
+// Conn runs fn with the session holding the lock, the heartbeat is skipped while fn runs so that the
+// statements of fn are not interleaved with it. It returns ErrLockLost when the lock was lost before.
+func (l *Lock) Conn(fn func(conn *sql.Conn) error) error {
+	l.connMu.Lock()
+	defer l.connMu.Unlock()
+
+	select {
+	case <-l.lost:
+		return fmt.Errorf("%w: `%s`", ErrLockLost, l.name)
+	default:
+	}
+
+	return fn(l.conn)
+}
+
+// Lost returns a channel closed once the session holding the lock is lost
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Unlock releases the lock and its session, it returns ErrLockLost when the lock was lost before
+func (l *Lock) Unlock(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stop) })
+	<-l.stopped
+
+	l.connMu.Lock()
+	defer l.connMu.Unlock()
+
+	select {
+	case <-l.lost:
+		return fmt.Errorf("%w: `%s`", ErrLockLost, l.name)
+	default:
+	}
+
+	released, err := l.session.unlock(ctx, l.key)
+	if err != nil {
+		// The session is ended, so that the lock is released by the database
+		l.session.discard()
+		return fmt.Errorf("release advisory lock `%s`: %w", l.name, err)
+	}
+
+	if !released {
+		l.session.discard()
+		return fmt.Errorf("%w: `%s`", ErrLockLost, l.name)
+	}
+
+	return l.session.Close()
+}
+
+// keepAlive checks the session holding the lock until the lock is released, the lock is lost once the
+// session does not respond
+func (l *Lock) keepAlive() {
+	defer close(l.stopped)
+
+	ticker := time.NewTicker(l.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+
+		// The session is in use, it is checked by the next heartbeat
+		if !l.connMu.TryLock() {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), l.heartbeat)
+		err := l.session.PingContext(ctx)
+		cancel()
+
+		if err != nil {
+			l.session.discard()
+			close(l.lost)
+			l.connMu.Unlock()
+			return
+		}
+		l.connMu.Unlock()
+	}
+}
+
+// session the connection of the database holding the advisory lock
+type session interface {
+	PingContext(ctx context.Context) error
+	// unlock releases the advisory lock of the key, it reports whether the session held it
+	unlock(ctx context.Context, key int64) (bool, error)
+	// discard ends the session, releasing its advisory locks
+	discard()
+	Close() error
+}
+
+// connSession the session of a connection of the pool
+type connSession struct {
+	*sql.Conn
+}
+
+func (s connSession) unlock(ctx context.Context, key int64) (bool, error) {
+	var released bool
+	err := s.QueryRowContext(ctx, `SELECT pg_advisory_unlock($1)`, key).Scan(&released)
+
+	return released, err
+}
+
+func (s connSession) discard() {
+	discard(s.Conn)
+}
+
+// discard closes the connection instead of returning it to the pool, ending its session and releasing
+// its advisory locks
+func discard(conn *sql.Conn) {
+	_ = conn.Raw(func(any) error {
+		return driver.ErrBadConn
+	})
+	_ = conn.Close()
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSession a session whose ping and unlock outcomes are set by the test
+type fakeSession struct {
+	mu        sync.Mutex
+	pingErr   error
+	pings     int
+	released  bool
+	unlockErr error
+	discarded bool
+	closed    bool
+}
+
+func (s *fakeSession) PingContext(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pings++
+	return s.pingErr
+}
+
+func (s *fakeSession) unlock(ctx context.Context, key int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.released, s.unlockErr
+}
+
+func (s *fakeSession) discard() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.discarded = true
+}
+
+func (s *fakeSession) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	return nil
+}
+
+func (s *fakeSession) state() (pings int, discarded, closed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.pings, s.discarded, s.closed
+}
+
+func TestLock_Unlock(t *testing.T) {
+	tcs := map[string]struct {
+		session      *fakeSession
+		expErr       error
+		expDiscarded bool
+		expClosed    bool
+	}{
+		"released": {
+			session:   &fakeSession{released: true},
+			expClosed: true,
+		},
+		"not held": {
+			session:      &fakeSession{},
+			expErr:       ErrLockLost,
+			expDiscarded: true,
+		},
+		"unlock failed": {
+			session:      &fakeSession{unlockErr: errors.New("connection reset")},
+			expDiscarded: true,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			l := newLock("scheduler", nil, tc.session, LockHeartbeat(time.Hour))
+
+			// When
+			err := l.Unlock(context.Background())
+
+			// Then
+			switch {
+			case tc.expErr != nil:
+				require.ErrorIs(t, err, tc.expErr)
+			case tc.expDiscarded:
+				require.Error(t, err)
+			default:
+				require.NoError(t, err)
+			}
+
+			_, discarded, closed := tc.session.state()
+			require.Equal(t, tc.expDiscarded, discarded)
+			require.Equal(t, tc.expClosed, closed)
+		})
+	}
+}
+
+func TestLock_KeepAliveLost(t *testing.T) {
+	// Given
+	s := &fakeSession{pingErr: errors.New("connection reset")}
+
+	// When
+	l := newLock("scheduler", nil, s, LockHeartbeat(time.Millisecond))
+
+	// Then
+	select {
+	case <-l.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lock is not lost")
+	}
+
+	_, discarded, _ := s.state()
+	require.True(t, discarded)
+	require.ErrorIs(t, l.Unlock(context.Background()), ErrLockLost)
+	require.ErrorIs(t, l.Conn(func(conn *sql.Conn) error { return nil }), ErrLockLost)
+}
+
+func TestLock_KeepAliveSkippedWhileInUse(t *testing.T) {
+	// Given
+	s := &fakeSession{released: true}
+	l := newLock("migrate", nil, s, LockHeartbeat(time.Millisecond))
+
+	// When
+	err := l.Conn(func(conn *sql.Conn) error {
+		pings, _, _ := s.state()
+		time.Sleep(20 * time.Millisecond)
+
+		// Then
+		after, _, _ := s.state()
+		require.Equal(t, pings, after)
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.NoError(t, l.Unlock(context.Background()))
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/virsavik/alchemist-template/pkg/backoff"
+	"github.com/virsavik/alchemist-template/pkg/config"
+	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/metrics"
+)
+
+const poolScopeName = "github.com/virsavik/alchemist-template/pkg/postgres/pool"
+
+// Open opens the connection pool of the database with the pool settings of the configuration, the
+// connections are not established until Connect or the first query
+func Open(cfg config.PGConfig) (*sql.DB, error) {
+	db, err := sql.Open("pgx", cfg.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	return db, nil
+}
+
+// Connect pings the database until it succeeds, waiting with the backoff between the attempts, so that
+// the application starting with the database does not fail. It returns the last error once ctx is done.
+func Connect(ctx context.Context, db *sql.DB, bo backoff.Exponential, log logger.Logger) error {
+	for attempt := 0; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+
+		log.Warnf("connect to the database failed, attempt %d: %v", attempt+1, err)
+
+		if waitErr := bo.Wait(ctx, attempt); waitErr != nil {
+			return fmt.Errorf("connect to the database: %w", err)
+		}
+	}
+}
+
+// RegisterStats exports the statistics of the connection pool as gauges, they are observed on each
+// collection of the metrics
+func RegisterStats(db *sql.DB) {
+	meter := metrics.NewMeter(poolScopeName)
+
+	stat := func(fn func(s sql.DBStats) float64) func(ctx context.Context) (float64, []attribute.KeyValue) {
+		return func(ctx context.Context) (float64, []attribute.KeyValue) {
+			return fn(db.Stats()), nil
+		}
+	}
+
+	meter.Gauge("db.client.connections.max", "Max number of open connections allowed",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	meter.Gauge("db.client.connections.in_use", "Number of connections in use",
+		stat(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	meter.Gauge("db.client.connections.idle", "Number of idle connections",
+		stat(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	meter.Gauge("db.client.connections.wait_count", "Total number of connections waited for",
+		stat(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	meter.Gauge("db.client.connections.wait_time", "Total time in seconds blocked waiting for a new connection",
+		stat(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+	meter.Gauge("db.client.connections.closed_max_idle", "Total number of connections closed due to the max idle connections",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }))
+	meter.Gauge("db.client.connections.closed_max_idle_time", "Total number of connections closed due to the max idle time",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) }))
+	meter.Gauge("db.client.connections.closed_max_lifetime", "Total number of connections closed due to the max lifetime",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }))
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/virsavik/alchemist-template/pkg/di"
+)
+
+// TxKey is the key of the transaction bound to a dependency scope
+var TxKey = di.NewKey[ContextTransactor]("postgres.tx")
+
+// RegisterTx registers a scoped transaction into the container. The transaction begins when it is
+// resolved for the first time within a scope, it is committed when the scope ends successfully and
+// rolled back when the scope ends with a cause.
+func RegisterTx(c di.Container, db ContextBeginner) {
+	di.AddScoped(c, TxKey, func(c di.Container) (ContextTransactor, error) {
+		// The transaction lifetime is controlled by the scope instead of the request context
+		return db.BeginTx(context.Background(), nil)
+	}, di.WithDisposer(func(ctx context.Context, tx ContextTransactor, cause error) error {
+		if cause != nil {
+			return tx.Rollback()
+		}
+
+		return tx.Commit()
+	}))
+}
+
+// ExecutorFromCtx returns the transaction bound to the dependency scope on context, it returns db when
+// the context does not carry a scope with a registered transaction
+func ExecutorFromCtx(ctx context.Context, db ContextExecutor) ContextExecutor {
+	if !di.IsRegistered(ctx, TxKey.String()) {
+		return db
+	}
+
+	return di.ResolveCtx(ctx, TxKey)
+}
+
+// UnitOfWork runs functions within the transaction bound to a dependency scope
+type UnitOfWork struct {
+	c di.Container
+}
+
+// NewUnitOfWork creates a UnitOfWork opening scopes from the container, the container must have
+// the transaction registered by RegisterTx
+func NewUnitOfWork(c di.Container) UnitOfWork {
+	return UnitOfWork{c: c}
+}
+
+// WithinTx runs fn within a transaction. When the context already carries a transaction scope,
+// e.g. opened by the unit of work middleware, fn joins it and the owner of the scope decides to
+// commit or roll back. Otherwise, a new scope is opened, and it is committed when fn returns nil
+// and rolled back when fn returns an error or panics.
+func (u UnitOfWork) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if di.IsRegistered(ctx, TxKey.String()) {
+		// Begin the transaction of the scope if it has not begun yet
+		di.ResolveCtx(ctx, TxKey)
+
+		return fn(ctx)
+	}
+
+	ctx = u.c.Scoped(ctx)
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = di.EndScope(ctx, fmt.Errorf("panic: %v", p))
+			panic(p)
+		}
+	}()
+
+	err = fn(ctx)
+
+	if endErr := di.EndScope(ctx, err); endErr != nil {
+		return errors.Join(err, endErr)
+	}
+
+	return err
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/virsavik/alchemist-template/pkg/iam"
@@ -64,6 +65,10 @@ func getUserProfileFromRequest(r *http.Request, validator validator.Validator) (
 		return iam.UserProfile{}, err
 	}
 
+	if validator == nil {
+		return iam.UserProfile{}, errors.New("iam validator is not configured")
+	}
+
 	// Let secure process the request. If it returns an error,
 	// that indicates the request should not continue.
 	parsedToken, err := validator.ValidateToken(r.Context(), tokenRaw)
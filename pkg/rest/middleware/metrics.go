@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+
+	"github.com/virsavik/alchemist-template/pkg/metrics"
+)
+
+const metricsScopeName = "github.com/virsavik/alchemist-template/pkg/rest/middleware/metrics"
+
+// OtelMetrics is a middleware function that records the RED metrics of an HTTP server: the number
+// of requests, the duration of the requests and the number of in-flight requests.
+//
+// The requests are recorded with their method, their route pattern rather than their path, so that
+// the cardinality of the metrics is bounded, and their response status code.
+//
+// Example usage:
+//
+//	r := chi.NewRouter()
+//	r.Use(middleware.OtelTracer())
+//	r.Use(middleware.OtelMetrics())
+func OtelMetrics() func(next http.Handler) http.Handler {
+	meter := metrics.NewMeter(metricsScopeName)
+	requests := meter.Counter("http.server.requests", "Number of HTTP requests served")
+	duration := meter.Histogram("http.server.request.duration", "Duration of the HTTP requests", "s")
+	inFlight := meter.UpDownCounter("http.server.active_requests", "Number of in-flight HTTP requests")
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			method := semconv.HTTPRequestMethodKey.String(r.Method)
+
+			inFlight.Add(ctx, 1, method)
+			defer inFlight.Add(ctx, -1, method)
+
+			started := time.Now()
+
+			// Wrap the response writer to get status_code easily
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r)
+
+			// The route pattern is known once the request has been routed
+			route := "unmatched"
+			if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			// The handlers writing nothing respond with 200 OK
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			attrs := []attribute.KeyValue{
+				method,
+				semconv.HTTPRoute(route),
+				semconv.HTTPResponseStatusCode(status),
+			}
+
+			requests.Add(ctx, 1, attrs...)
+			duration.Record(ctx, time.Since(started).Seconds(), attrs...)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/virsavik/alchemist-template/pkg/iam"
+	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/rest/httpio"
+)
+
+var ErrForbidden = httpio.Error{Status: http.StatusForbidden, Code: "forbidden", Desc: "scope is not granted"}
+
+// RequireScope is a middleware function that restricts the requests to the users granted the scope, it
+// responds with a forbidden status otherwise. It must be used after the Authenticator middleware.
+func RequireScope(scope string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			p := iam.FromCtx(r.Context())
+			if !p.HasScope(scope) {
+				logger.FromCtx(r.Context()).Infof("user `%s` is not granted the scope `%s`", p.ID, scope)
+
+				httpio.WriteJSON(w, r, httpio.Response[httpio.Message]{
+					Status: ErrForbidden.Status,
+					Body: httpio.Message{
+						Code: ErrForbidden.Code,
+						Desc: ErrForbidden.Desc,
+					},
+				})
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/virsavik/alchemist-template/pkg/di"
+	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/rest/httpio"
+)
+
+// UnitOfWork is a middleware function that opens a dependency scope from the given container for each
+// request, so that every scoped dependency, e.g. the transaction registered by postgres.RegisterTx, is
+// shared by the handler, services and repositories serving the request.
+//
+// The scope ends right before the response status is written: successfully when the handler responds
+// with a status lower than 400 (the transaction is committed), with a cause otherwise (the transaction
+// is rolled back). When ending the scope successfully fails, e.g. the commit fails, the response is
+// replaced by a 500 Internal Server Error. When the handler panics, the scope ends with the panic as
+// cause and the panic is propagated to the Recover middleware.
+//
+// The response writer stays a http.Flusher and a http.Hijacker: flushing ends the scope as writing the
+// status does, hijacking the connection ends the scope successfully first and fails when it cannot.
+func UnitOfWork(c di.Container) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			r = r.WithContext(c.Scoped(r.Context()))
+			uw := &unitOfWorkWriter{ResponseWriter: w, r: r}
+
+			defer func() {
+				if p := recover(); p != nil {
+					_ = uw.end(fmt.Errorf("panic: %v", p))
+					panic(p)
+				}
+			}()
+
+			next.ServeHTTP(uw, r)
+
+			// End the scope of the handlers writing nothing
+			if !uw.ended {
+				uw.WriteHeader(http.StatusOK)
+			}
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+var (
+	_ http.Flusher  = (*unitOfWorkWriter)(nil)
+	_ http.Hijacker = (*unitOfWorkWriter)(nil)
+)
+
+// unitOfWorkWriter ends the dependency scope of the request before the response status is written
+type unitOfWorkWriter struct {
+	http.ResponseWriter
+	r       *http.Request
+	ended   bool
+	aborted bool
+}
+
+func (w *unitOfWorkWriter) WriteHeader(status int) {
+	if w.ended {
+		if !w.aborted {
+			w.ResponseWriter.WriteHeader(status)
+		}
+		return
+	}
+
+	var cause error
+	if status >= http.StatusBadRequest {
+		cause = fmt.Errorf("response status %d", status)
+	}
+
+	if err := w.end(cause); err != nil && cause == nil {
+		w.abort(err)
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *unitOfWorkWriter) Write(b []byte) (int, error) {
+	if !w.ended {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	// Discard the body of the replaced response
+	if w.aborted {
+		return len(b), nil
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush ends the scope before the status is flushed, e.g. by a streamed response
+func (w *unitOfWorkWriter) Flush() {
+	if !w.ended {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack ends the scope successfully before the connection is taken over by the handler, the connection
+// is not hijacked when ending the scope fails, e.g. the commit fails
+func (w *unitOfWorkWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+
+	if w.aborted {
+		return nil, nil, errors.New("unit of work failed, the response is replaced")
+	}
+
+	if !w.ended {
+		if err := w.end(nil); err != nil {
+			w.abort(err)
+			return nil, nil, fmt.Errorf("end unit of work: %w", err)
+		}
+	}
+
+	return h.Hijack()
+}
+
+// Unwrap returns the response writer of the server, see http.ResponseController
+func (w *unitOfWorkWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// abort replaces the successful response by a 500 Internal Server Error, the changes have not been
+// committed
+func (w *unitOfWorkWriter) abort(err error) {
+	w.aborted = true
+
+	logger.FromCtx(w.r.Context()).Errorf(err, "end unit of work error")
+	trace.SpanFromContext(w.r.Context()).RecordError(err, trace.WithStackTrace(true))
+
+	httpio.WriteJSON(w.ResponseWriter, w.r, httpio.Response[httpio.Message]{
+		Status: http.StatusInternalServerError,
+		Body:   httpio.MsgInternalServerError,
+	})
+}
+
+func (w *unitOfWorkWriter) end(cause error) error {
+	if w.ended {
+		return nil
+	}
+	w.ended = true
+
+	err := di.EndScope(w.r.Context(), cause)
+	if err != nil && cause != nil {
+		logger.FromCtx(w.r.Context()).Errorf(err, "end unit of work error")
+	}
+
+	return err
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/virsavik/alchemist-template/pkg/di"
+)
+
+func TestUnitOfWork_Flush(t *testing.T) {
+	tcs := map[string]struct {
+		commitErr error
+		expStatus int
+	}{
+		"committed": {
+			expStatus: http.StatusOK,
+		},
+		"commit failed": {
+			commitErr: errors.New("connection reset"),
+			expStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			var ended bool
+			c := di.New()
+			c.AddScoped("tx", func(c di.Container) (any, error) {
+				return "tx", nil
+			}, di.WithDisposer(func(ctx context.Context, v any, cause error) error {
+				ended = true
+				return tc.commitErr
+			}))
+
+			rec := httptest.NewRecorder()
+			h := UnitOfWork(c)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = di.Get(r.Context(), "tx")
+
+				// When
+				w.(http.Flusher).Flush()
+
+				// Then
+				require.True(t, ended)
+				require.True(t, rec.Flushed)
+			}))
+
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			require.Equal(t, tc.expStatus, rec.Code)
+		})
+	}
+}
+
+func TestUnitOfWork_HijackNotSupported(t *testing.T) {
+	// Given
+	var cause error
+	c := di.New()
+	c.AddScoped("tx", func(c di.Container) (any, error) {
+		return "tx", nil
+	}, di.WithDisposer(func(ctx context.Context, v any, c error) error {
+		cause = c
+		return nil
+	}))
+
+	rec := httptest.NewRecorder()
+	h := UnitOfWork(c)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = di.Get(r.Context(), "tx")
+
+		// When
+		_, _, err := w.(http.Hijacker).Hijack()
+
+		// Then
+		require.Error(t, err)
+		w.WriteHeader(http.StatusNotImplemented)
+	}))
+
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, http.StatusNotImplemented, rec.Code)
+	require.Error(t, cause)
+}
@@ -0,0 +1,89 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/virsavik/alchemist-template/pkg/iam"
+	"github.com/virsavik/alchemist-template/pkg/iam/extractor"
+	"github.com/virsavik/alchemist-template/pkg/iam/validator"
+	"github.com/virsavik/alchemist-template/pkg/logger"
+)
+
+// Authenticator is a unary server interceptor that handles JWT authentication like the Authenticator
+// HTTP middleware. It extracts the JWT token from the authorization metadata, validates it using the
+// provided IAM validator, and sets the user profile information in the request context. If
+// authentication fails, it responds with an Unauthenticated status.
+func Authenticator(v validator.Validator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		log := logger.FromCtx(ctx)
+
+		p, err := getUserProfileFromMetadata(ctx, v)
+		if err != nil {
+			log.Infof("user authenticate error: %v", err)
+
+			return nil, convertValidatorError(err)
+		}
+
+		// Set user profile in the request context
+		ctx = iam.SetInCtx(ctx, p)
+
+		// Enrich context with user ID for logging purposes
+		ctx = logger.SetInCtx(ctx, log.With(logger.String("enduser.id", p.ID)))
+
+		return handler(ctx, req)
+	}
+}
+
+// getUserProfileFromMetadata extracts the JWT token from the authorization metadata of the request,
+// validates it, and returns the user profile associated with the token.
+func getUserProfileFromMetadata(ctx context.Context, v validator.Validator) (iam.UserProfile, error) {
+	var authHeader string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			authHeader = values[0]
+		}
+	}
+
+	tokenRaw, err := extractor.BearerToken(authHeader)
+	if err != nil {
+		return iam.UserProfile{}, err
+	}
+
+	if v == nil {
+		return iam.UserProfile{}, errors.New("iam validator is not configured")
+	}
+
+	parsedToken, err := v.ValidateToken(ctx, tokenRaw)
+	if err != nil {
+		return iam.UserProfile{}, err
+	}
+
+	return iam.GetUserProfile(parsedToken)
+}
+
+var (
+	ErrUnauthorized = status.Error(codes.Unauthenticated, "token in unauthorized")
+	ErrExpired      = status.Error(codes.Unauthenticated, "token is expired")
+	ErrNBFInvalid   = status.Error(codes.Unauthenticated, "token nbf validation failed")
+	ErrIATInvalid   = status.Error(codes.Unauthenticated, "token iat validation failed")
+)
+
+// convertValidatorError will normalize the error message from the underlining
+func convertValidatorError(err error) error {
+	switch err.Error() {
+	case validator.ErrExpired.Error():
+		return ErrExpired
+	case validator.ErrIATInvalid.Error():
+		return ErrIATInvalid
+	case validator.ErrNBFInvalid.Error():
+		return ErrNBFInvalid
+	default:
+		return ErrUnauthorized
+	}
+}
@@ -0,0 +1,64 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecover(t *testing.T) {
+	// Given
+	info := &grpc.UnaryServerInfo{FullMethod: "/userspb.UsersService/GetUser"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	}
+
+	// When
+	resp, err := Recover()(context.Background(), nil, info, handler)
+
+	// Then
+	require.Nil(t, resp)
+	require.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestAuthenticator(t *testing.T) {
+	tcs := map[string]struct {
+		md     metadata.MD
+		expErr error
+	}{
+		"no validator": {
+			md:     metadata.Pairs("authorization", "Bearer token"),
+			expErr: ErrUnauthorized,
+		},
+		"invalid format": {
+			md:     metadata.Pairs("authorization", "Basic token"),
+			expErr: ErrUnauthorized,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			called := false
+			ctx := metadata.NewIncomingContext(context.Background(), tc.md)
+			handler := func(ctx context.Context, req any) (any, error) {
+				called = true
+				return nil, nil
+			}
+
+			// When
+			_, err := Authenticator(nil)(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+
+			// Then
+			require.ErrorIs(t, err, tc.expErr)
+			require.Equal(t, codes.Unauthenticated, status.Code(err))
+			require.False(t, called)
+		})
+	}
+}
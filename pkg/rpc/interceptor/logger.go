@@ -0,0 +1,41 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+)
+
+// Logger is a unary server interceptor that provides request logging capabilities like the Logger HTTP
+// middleware. It logs the method, the status code and the duration of each request, and sets the
+// provided logger in the request context to allow the handlers to access and use the same logger instance.
+func Logger(l logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		started := time.Now()
+
+		defer func() {
+			var remoteAddress string
+			if p, ok := peer.FromContext(ctx); ok {
+				remoteAddress = p.Addr.String()
+			}
+
+			reqLogger := l.With(
+				logger.String("rpc.method", info.FullMethod),
+				logger.String("rpc.grpc.status_code", status.Code(err).String()),
+				logger.String("rpc.request.remote_address", remoteAddress),
+				logger.String("rpc.duration", time.Since(started).String()),
+				logger.String("trace.id", trace.SpanFromContext(ctx).SpanContext().TraceID().String()),
+			)
+
+			reqLogger.Infof("Served")
+		}()
+
+		return handler(logger.SetInCtx(ctx, l), req)
+	}
+}
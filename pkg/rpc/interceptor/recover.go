@@ -0,0 +1,43 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+)
+
+// Recover is a unary server interceptor that recovers from panics in the handlers like the Recover HTTP
+// middleware. It logs the panic details, captures the entire stack trace, and returns an Internal status.
+func Recover() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, rErr error) {
+		defer func() {
+			// Recover from the panic and obtain the panic value.
+			if p := recover(); p != nil {
+				err, ok := p.(error)
+				if !ok {
+					err = fmt.Errorf("%+v", p)
+				}
+
+				// Capture and log the entire stack trace along with the error details.
+				logger.FromCtx(ctx).Errorf(err, "caught a panic, stacktrace: %s", debug.Stack())
+
+				// Record error
+				span := trace.SpanFromContext(ctx)
+				span.SetAttributes(attribute.Bool("error", true))
+				span.RecordError(err, trace.WithStackTrace(true))
+
+				resp, rErr = nil, status.Error(codes.Internal, "internal server error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,84 @@
+package interceptor
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	scopeName = "github.com/virsavik/alchemist-template/pkg/rpc/interceptor/tracer"
+	version   = "1.0.0"
+)
+
+// OtelTracer is a unary server interceptor that provides OpenTelemetry (OTel) tracing capabilities like
+// the OtelTracer HTTP middleware. It extracts the trace context propagated in the request metadata and
+// starts a server span for each request, with the method and the status code of the response.
+func OtelTracer() grpc.UnaryServerInterceptor {
+	tracer := otel.GetTracerProvider().Tracer(scopeName,
+		trace.WithInstrumentationVersion(version),
+	)
+	propagators := otel.GetTextMapPropagator()
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		ctx = propagators.Extract(ctx, metadataCarrier(md))
+
+		ctx, span := tracer.Start(
+			ctx,
+			info.FullMethod,
+			trace.WithAttributes(
+				semconv.RPCSystemGRPC,
+				attribute.String("rpc.method", info.FullMethod),
+			),
+			trace.WithSpanKind(trace.SpanKindServer),
+		)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+
+		// Add the status code as an attribute to the span.
+		st := status.Convert(err)
+		span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(st.Code())))
+		if err != nil {
+			span.SetStatus(codes.Error, st.Message())
+		}
+
+		return resp, err
+	}
+}
+
+// metadataCarrier adapts the gRPC metadata to a propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+var _ propagation.TextMapCarrier = metadataCarrier{}
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
@@ -0,0 +1,28 @@
+package scheduler
+
+import (
+	"time"
+)
+
+type JobOption func(j *job)
+
+// Jitter delays each run by a random duration up to d, so that the replicas do not run the job at once
+func Jitter(d time.Duration) JobOption {
+	return func(j *job) {
+		j.jitter = d
+	}
+}
+
+// Timeout cancels the context of a run once d elapsed
+func Timeout(d time.Duration) JobOption {
+	return func(j *job) {
+		j.timeout = d
+	}
+}
+
+// AllowOverlap runs the job on schedule even though its previous run is still running
+func AllowOverlap() JobOption {
+	return func(j *job) {
+		j.allowOverlap = true
+	}
+}
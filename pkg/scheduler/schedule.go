@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCron = errors.New("cron expression is invalid")
+
+// Schedule returns the next time a job runs after t
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// Every schedules a job at a fixed interval, e.g. every 5 minutes from the start of the scheduler
+func Every(d time.Duration) Schedule {
+	return every{interval: d}
+}
+
+type every struct {
+	interval time.Duration
+}
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(e.interval)
+}
+
+// Cron parses a cron expression with the standard 5 fields: minute, hour, day of month, month and day
+// of week, e.g. "30 3 * * *" runs at 03:30 every day. The fields accept "*", values, ranges "1-5",
+// lists "1,15" and steps "*/10". The descriptors @hourly, @daily, @weekly, @monthly, @yearly and
+// "@every <duration>" are accepted as well. The times are in the location of the time passed to Next.
+func Cron(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	switch expr {
+	case "@hourly":
+		expr = "0 * * * *"
+	case "@daily", "@midnight":
+		expr = "0 0 * * *"
+	case "@weekly":
+		expr = "0 0 * * 0"
+	case "@monthly":
+		expr = "0 0 1 * *"
+	case "@yearly", "@annually":
+		expr = "0 0 1 1 *"
+	}
+
+	if d, ok := strings.CutPrefix(expr, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("%w: `%s`: interval is invalid", ErrInvalidCron, expr)
+		}
+
+		return Every(interval), nil
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: `%s`: 5 fields are expected, got %d", ErrInvalidCron, expr, len(fields))
+	}
+
+	var (
+		c   cron
+		err error
+	)
+	for idx, b := range []struct {
+		field    *uint64
+		min, max int
+	}{
+		{&c.minutes, 0, 59},
+		{&c.hours, 0, 23},
+		{&c.days, 1, 31},
+		{&c.months, 1, 12},
+		{&c.weekdays, 0, 7},
+	} {
+		if *b.field, err = parseField(fields[idx], b.min, b.max); err != nil {
+			return nil, fmt.Errorf("%w: `%s`: %v", ErrInvalidCron, expr, err)
+		}
+	}
+
+	// Sunday is either 0 or 7 in the day of week
+	if has(c.weekdays, 7) {
+		c.weekdays = c.weekdays&^(1<<7) | 1
+	}
+
+	// The day matches either the day of month or the day of week when both are restricted
+	c.anyDay = fields[2] == "*" || fields[4] == "*"
+
+	return c, nil
+}
+
+// MustCron is like Cron but panics when the expression is invalid, e.g. for the schedules of the modules
+func MustCron(expr string) Schedule {
+	s, err := Cron(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// cron the bitsets of the values of the fields matching the expression
+type cron struct {
+	minutes, hours, days, months, weekdays uint64
+	anyDay                                 bool
+}
+
+// maxYears bounds the search of the next time, e.g. "0 0 30 2 *" never matches
+const maxYears = 5
+
+func (c cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxYears, 0, 0)
+
+	for t.Before(limit) {
+		if !has(c.months, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !has(c.hours, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !has(c.minutes, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (c cron) matchDay(t time.Time) bool {
+	day, weekday := has(c.days, t.Day()), has(c.weekdays, int(t.Weekday()))
+	if c.anyDay {
+		return day && weekday
+	}
+
+	return day || weekday
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
+
+// parseField parses a comma separated list of values, ranges and steps into a bitset
+func parseField(field string, min, max int) (uint64, error) {
+	var set uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("step `%s` is invalid", part)
+			}
+		}
+
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			loStr, hiStr, _ := strings.Cut(rng, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(loStr)
+			hi, err2 = strconv.Atoi(hiStr)
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("range `%s` is invalid", part)
+			}
+		default:
+			v, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("value `%s` is invalid", part)
+			}
+			lo, hi = v, v
+			if hasStep {
+				// "5/10" starts at 5 and steps until the max
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("`%s` is out of the range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+
+	return set, nil
+}
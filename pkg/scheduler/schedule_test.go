@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCron_Next(t *testing.T) {
+	// Wednesday
+	from := time.Date(2024, time.January, 10, 14, 7, 30, 0, time.UTC)
+
+	tcs := map[string]struct {
+		expr    string
+		expNext time.Time
+		expErr  error
+	}{
+		"every minute": {
+			expr:    "* * * * *",
+			expNext: time.Date(2024, time.January, 10, 14, 8, 0, 0, time.UTC),
+		},
+		"step": {
+			expr:    "*/15 * * * *",
+			expNext: time.Date(2024, time.January, 10, 14, 15, 0, 0, time.UTC),
+		},
+		"daily": {
+			expr:    "30 3 * * *",
+			expNext: time.Date(2024, time.January, 11, 3, 30, 0, 0, time.UTC),
+		},
+		"list and range": {
+			expr:    "0 9-17 * * 1,5",
+			expNext: time.Date(2024, time.January, 12, 9, 0, 0, 0, time.UTC),
+		},
+		"sunday as 7": {
+			expr:    "0 0 * * 7",
+			expNext: time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC),
+		},
+		"day of month or day of week": {
+			expr:    "0 0 1 * 5",
+			expNext: time.Date(2024, time.January, 12, 0, 0, 0, 0, time.UTC),
+		},
+		"descriptor": {
+			expr:    "@monthly",
+			expNext: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"every": {
+			expr:    "@every 90s",
+			expNext: from.Add(90 * time.Second),
+		},
+		"never matches": {
+			expr:    "0 0 30 2 *",
+			expNext: time.Time{},
+		},
+		"fields missing": {
+			expr:   "0 0 * *",
+			expErr: ErrInvalidCron,
+		},
+		"out of range": {
+			expr:   "60 * * * *",
+			expErr: ErrInvalidCron,
+		},
+		"invalid step": {
+			expr:   "*/0 * * * *",
+			expErr: ErrInvalidCron,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// When
+			s, err := Cron(tc.expr)
+
+			// Then
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expNext, s.Next(from))
+		})
+	}
+}
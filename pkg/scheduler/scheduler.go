@@ -0,0 +1,279 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+)
+
+const scopeName = "github.com/virsavik/alchemist-template/pkg/scheduler"
+
+var _ Registry = (*Scheduler)(nil)
+
+// JobFunc is the function of a job, the logger of the run is on ctx, see logger.FromCtx
+type JobFunc func(ctx context.Context) error
+
+// Registry registers the recurring jobs of the modules
+//
+//	svc.Scheduler().Register("purge deleted users", scheduler.MustCron("30 3 * * *"), purge,
+//		scheduler.Jitter(time.Minute))
+type Registry interface {
+	// Register registers the job running on the schedule, it panics when the name is already registered
+	Register(name string, schedule Schedule, fn JobFunc, opts ...JobOption)
+}
+
+// Scheduler runs the registered jobs on their schedules. A run is skipped while the previous run of
+// the job is still running, unless the job allows overlapping runs.
+type Scheduler struct {
+	logger logger.Logger
+	tracer trace.Tracer
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	started bool
+	closed  bool
+	// runCtx is the context of the current Run, the runs started by the Run are tracked by runs
+	runCtx context.Context
+	runs   *sync.WaitGroup
+
+	// jobsCtx is canceled when the runs overrun the deadline of Close, canceling the runs
+	jobsCtx    context.Context
+	cancelJobs context.CancelFunc
+	running    sync.WaitGroup
+}
+
+type job struct {
+	name         string
+	schedule     Schedule
+	fn           JobFunc
+	jitter       time.Duration
+	timeout      time.Duration
+	allowOverlap bool
+
+	mu      sync.Mutex
+	running int
+}
+
+// New creates a scheduler, the jobs are logged with the logger
+func New(log logger.Logger) *Scheduler {
+	jobsCtx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		logger:     log,
+		tracer:     otel.Tracer(scopeName),
+		jobs:       make(map[string]*job),
+		jobsCtx:    jobsCtx,
+		cancelJobs: cancel,
+	}
+}
+
+func (s *Scheduler) Register(name string, schedule Schedule, fn JobFunc, opts ...JobOption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[name]; exists {
+		panic(fmt.Sprintf("job `%s` is already registered", name))
+	}
+
+	j := &job{name: name, schedule: schedule, fn: fn}
+	for _, opt := range opts {
+		opt(j)
+	}
+	s.jobs[name] = j
+
+	// The jobs registered once the scheduler runs are scheduled right away
+	if s.started {
+		go s.loop(s.runCtx, s.runs, j)
+	}
+}
+
+// Run schedules the jobs until ctx is done, the context of the runs is canceled once ctx is done and Run
+// returns once they are done, so that no run outlives the leadership, see postgres.Leader. Run may be
+// called again once it returned, e.g. when the leadership is acquired back.
+func (s *Scheduler) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The runs are canceled as well when they overrun the deadline of Close
+	go func() {
+		select {
+		case <-s.jobsCtx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	runs := &sync.WaitGroup{}
+
+	s.mu.Lock()
+	s.started, s.runCtx, s.runs = true, ctx, runs
+	for _, j := range s.jobs {
+		go s.loop(ctx, runs, j)
+	}
+	s.mu.Unlock()
+
+	<-ctx.Done()
+
+	// A run starts with the lock held while ctx is not done, so that no run starts once the lock is
+	// taken here and the runs waited are every run of this Run
+	s.mu.Lock()
+	s.started = false
+	s.mu.Unlock()
+
+	runs.Wait()
+
+	return nil
+}
+
+// Close waits for the runs in progress, their context is canceled once ctx is done and the error of ctx
+// is returned
+func (s *Scheduler) Close(ctx context.Context) error {
+	// No run starts once closed, so that the runs waited are the runs in progress
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancelJobs()
+		return nil
+	case <-ctx.Done():
+		s.cancelJobs()
+		return ctx.Err()
+	}
+}
+
+// loop runs the job on its schedule until ctx is done, the runs are tracked by runs
+func (s *Scheduler) loop(ctx context.Context, runs *sync.WaitGroup, j *job) {
+	for {
+		next := j.schedule.Next(time.Now())
+		if next.IsZero() {
+			s.logger.Warnf("job `%s` has no next run, it is not scheduled anymore", j.name)
+			return
+		}
+
+		delay := time.Until(next)
+		if j.jitter > 0 {
+			delay += time.Duration(rand.Int63n(int64(j.jitter)))
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !s.start(ctx, runs, j) {
+			continue
+		}
+
+		go func() {
+			defer s.running.Done()
+			defer runs.Done()
+			defer j.finish()
+
+			s.run(ctx, j)
+		}()
+	}
+}
+
+// start reports whether the job starts running, it does not when the scheduler is closed, ctx is done or
+// the previous run of the job is still running
+func (s *Scheduler) start(ctx context.Context, runs *sync.WaitGroup, j *job) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || ctx.Err() != nil {
+		return false
+	}
+
+	if !j.tryStart() {
+		s.logger.Warnf("job `%s` is skipped, the previous run is still running", j.name)
+		return false
+	}
+
+	s.running.Add(1)
+	runs.Add(1)
+
+	return true
+}
+
+// run runs the job once within a span, with a logger of the run on the context
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	ctx, span := s.tracer.Start(ctx, "scheduler.run "+j.name,
+		trace.WithAttributes(attribute.String("job.name", j.name)),
+		trace.WithNewRoot(),
+	)
+	defer span.End()
+
+	log := s.logger.With(
+		logger.String("job.name", j.name),
+		logger.String("trace.id", span.SpanContext().TraceID().String()),
+	)
+	ctx = logger.SetInCtx(ctx, log)
+
+	if j.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	log.Infof("job `%s` started", j.name)
+
+	if err := call(ctx, j.fn); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Errorf(err, "job `%s` failed after %s", j.name, time.Since(started))
+		return
+	}
+
+	log.Infof("job `%s` completed in %s", j.name, time.Since(started))
+}
+
+// call calls the job, recovering its panic as an error
+func call(ctx context.Context, fn JobFunc) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return fn(ctx)
+}
+
+func (j *job) tryStart() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.running > 0 && !j.allowOverlap {
+		return false
+	}
+	j.running++
+
+	return true
+}
+
+func (j *job) finish() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.running--
+}
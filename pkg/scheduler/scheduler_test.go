@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+)
+
+func TestScheduler_Run(t *testing.T) {
+	tcs := map[string]struct {
+		opts        []JobOption
+		expOverlaps bool
+	}{
+		"overlap prevented": {},
+		"overlap allowed": {
+			opts:        []JobOption{AllowOverlap()},
+			expOverlaps: true,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			s := New(logger.NewNoop())
+
+			var running, maxRunning, runs atomic.Int32
+			s.Register("refresh cache", Every(10*time.Millisecond), func(ctx context.Context) error {
+				n := running.Add(1)
+				defer running.Add(-1)
+				runs.Add(1)
+
+				for {
+					max := maxRunning.Load()
+					if n <= max || maxRunning.CompareAndSwap(max, n) {
+						break
+					}
+				}
+
+				time.Sleep(35 * time.Millisecond)
+				return nil
+			}, tc.opts...)
+
+			ctx, cancel := context.WithCancel(context.Background())
+
+			// When
+			go func() { _ = s.Run(ctx) }()
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+			err := s.Close(context.Background())
+
+			// Then
+			require.NoError(t, err)
+			require.Equal(t, int32(0), running.Load())
+			require.Greater(t, runs.Load(), int32(1))
+			require.Equal(t, tc.expOverlaps, maxRunning.Load() > 1)
+		})
+	}
+}
+
+func TestScheduler_CloseDeadline(t *testing.T) {
+	// Given
+	s := New(logger.NewNoop())
+
+	canceled := make(chan struct{})
+	s.Register("purge", Every(time.Millisecond), func(ctx context.Context) error {
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	})
+
+	// The runs are waited by Close while the scheduler still runs, e.g. the leadership is kept
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.Run(ctx) }()
+	time.Sleep(20 * time.Millisecond)
+
+	// When
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer closeCancel()
+	err := s.Close(closeCtx)
+
+	// Then
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	<-canceled
+}
+
+func TestScheduler_RunCanceled(t *testing.T) {
+	// Given
+	s := New(logger.NewNoop())
+
+	started := make(chan struct{})
+	var canceled atomic.Bool
+	s.Register("purge", Every(time.Millisecond), func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		canceled.Store(true)
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+	<-started
+
+	// When
+	cancel()
+
+	// Then
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("run is not canceled")
+	}
+	require.True(t, canceled.Load())
+	require.NoError(t, s.Close(context.Background()))
+}
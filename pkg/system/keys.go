@@ -0,0 +1,26 @@
+package system
+
+import (
+	"database/sql"
+
+	"github.com/virsavik/alchemist-template/pkg/config"
+	"github.com/virsavik/alchemist-template/pkg/di"
+	"github.com/virsavik/alchemist-template/pkg/events"
+	"github.com/virsavik/alchemist-template/pkg/iam/validator"
+	"github.com/virsavik/alchemist-template/pkg/jobs"
+	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/scheduler"
+	"github.com/virsavik/alchemist-template/pkg/waiter"
+)
+
+// Keys of the dependencies registered by the system into the root container
+var (
+	ConfigKey    = di.NewKey[config.AppConfig]("system.config")
+	DBKey        = di.NewKey[*sql.DB]("system.db")
+	LoggerKey    = di.NewKey[logger.Logger]("system.logger")
+	ValidatorKey = di.NewKey[validator.Validator]("system.validator")
+	WaiterKey    = di.NewKey[waiter.Waiter]("system.waiter")
+	EventsKey    = di.NewKey[events.Bus]("system.events")
+	SchedulerKey = di.NewKey[scheduler.Registry]("system.scheduler")
+	JobsKey      = di.NewKey[jobs.Queue]("system.jobs")
+)
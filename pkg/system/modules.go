@@ -0,0 +1,188 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/virsavik/alchemist-template/pkg/health"
+	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/waiter"
+)
+
+const modulesScopeName = "github.com/virsavik/alchemist-template/pkg/system/modules"
+
+var (
+	ErrModuleDuplicated = errors.New("module is duplicated")
+	ErrModuleNotFound   = errors.New("module is not found")
+	ErrModuleCyclic     = errors.New("cyclic module dependencies")
+)
+
+// StartupModules starts the modules in the order of their dependencies, the modules are shut down in
+// the reverse order when the waiter stops the workers. The ready modules are checked by the readiness
+// probe.
+//
+// The startup fails before starting any module when the dependencies are missing or cyclic, and on
+// the first module failing to start.
+func StartupModules(ctx context.Context, svc Service, modules ...Module) error {
+	ordered, err := SortModules(modules)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := otel.Tracer(modulesScopeName).Start(ctx, "modules.startup")
+	defer span.End()
+
+	for _, module := range ordered {
+		if err := startupModule(ctx, svc, module); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+
+		if m, ok := module.(ReadyModule); ok {
+			svc.Health().Register("module "+m.Name(), health.Readiness, m.Ready)
+		}
+	}
+
+	svc.Waiter().Cleanup(waiter.PhaseStopWorkers, "modules", shutdownModules(svc, ordered))
+
+	return nil
+}
+
+func startupModule(ctx context.Context, svc Service, module Module) error {
+	ctx, span := otel.Tracer(modulesScopeName).Start(ctx, "modules.startup "+module.Name(),
+		trace.WithAttributes(attribute.String("module.name", module.Name())),
+	)
+	defer span.End()
+
+	started := time.Now()
+
+	if err := module.Startup(ctx, moduleService{Service: svc, logger: svc.Logger().Named(module.Name())}); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		svc.Logger().Errorf(err, "module `%s` failed to start after %s", module.Name(), time.Since(started))
+
+		return fmt.Errorf("start module `%s`: %w", module.Name(), err)
+	}
+
+	svc.Logger().Infof("module `%s` started in %s", module.Name(), time.Since(started))
+
+	return nil
+}
+
+// moduleService the service of a module, the module logs with the logger named after it
+type moduleService struct {
+	Service
+	logger logger.Logger
+}
+
+func (s moduleService) Logger() logger.Logger {
+	return s.logger
+}
+
+// shutdownModules returns the cleanup shutting down the modules one by one in the reverse order of
+// their startup, so that a module is shut down before the modules it depends on
+func shutdownModules(svc Service, started []Module) waiter.CleanupFunc {
+	return func(ctx context.Context) error {
+		var errs []error
+		for i := len(started) - 1; i >= 0; i-- {
+			m, ok := started[i].(ShutdownModule)
+			if !ok {
+				continue
+			}
+
+			if err := m.Shutdown(ctx); err != nil {
+				svc.Logger().Errorf(err, "module `%s` failed to shut down", m.Name())
+				errs = append(errs, fmt.Errorf("shut down module `%s`: %w", m.Name(), err))
+				continue
+			}
+
+			svc.Logger().Infof("module `%s` shut down", m.Name())
+		}
+
+		return errors.Join(errs...)
+	}
+}
+
+// SortModules sorts the modules in the order of their dependencies, the modules without dependencies
+// between them keep their order
+func SortModules(modules []Module) ([]Module, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	byName := make(map[string]Module, len(modules))
+	for _, m := range modules {
+		if _, exists := byName[m.Name()]; exists {
+			return nil, fmt.Errorf("%w: `%s`", ErrModuleDuplicated, m.Name())
+		}
+		byName[m.Name()] = m
+	}
+
+	var (
+		rs    = make([]Module, 0, len(modules))
+		state = make(map[string]int, len(modules))
+		path  []string
+		visit func(m Module) error
+	)
+
+	visit = func(m Module) error {
+		state[m.Name()] = visiting
+		path = append(path, m.Name())
+
+		for _, name := range dependenciesOf(m) {
+			dep, exists := byName[name]
+			if !exists {
+				return fmt.Errorf("%w: module `%s` depends on `%s`", ErrModuleNotFound, m.Name(), name)
+			}
+
+			switch state[name] {
+			case visiting:
+				// the dependency is on the current path, report the path from it
+				for i := range path {
+					if path[i] == name {
+						cycle := append(append([]string{}, path[i:]...), name)
+						return fmt.Errorf("%w: %s", ErrModuleCyclic, strings.Join(cycle, " -> "))
+					}
+				}
+			case 0:
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[m.Name()] = visited
+		rs = append(rs, m)
+
+		return nil
+	}
+
+	for _, m := range modules {
+		if state[m.Name()] == 0 {
+			if err := visit(m); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return rs, nil
+}
+
+func dependenciesOf(m Module) []string {
+	if dm, ok := m.(DependentModule); ok {
+		return dm.DependsOn()
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package system
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testModule struct {
+	name      string
+	dependsOn []string
+}
+
+func (m testModule) Name() string {
+	return m.name
+}
+
+func (m testModule) DependsOn() []string {
+	return m.dependsOn
+}
+
+func (m testModule) Startup(context.Context, Service) error {
+	return nil
+}
+
+func TestSortModules(t *testing.T) {
+	tcs := map[string]struct {
+		modules  []Module
+		expOrder []string
+		expErr   error
+	}{
+		"no dependencies": {
+			modules: []Module{
+				testModule{name: "users"},
+				testModule{name: "orders"},
+			},
+			expOrder: []string{"users", "orders"},
+		},
+		"dependencies first": {
+			modules: []Module{
+				testModule{name: "notifications", dependsOn: []string{"orders", "users"}},
+				testModule{name: "orders", dependsOn: []string{"users"}},
+				testModule{name: "users"},
+			},
+			expOrder: []string{"users", "orders", "notifications"},
+		},
+		"missing dependency": {
+			modules: []Module{
+				testModule{name: "orders", dependsOn: []string{"users"}},
+			},
+			expErr: ErrModuleNotFound,
+		},
+		"cyclic dependencies": {
+			modules: []Module{
+				testModule{name: "users", dependsOn: []string{"orders"}},
+				testModule{name: "orders", dependsOn: []string{"users"}},
+			},
+			expErr: ErrModuleCyclic,
+		},
+		"duplicated module": {
+			modules: []Module{
+				testModule{name: "users"},
+				testModule{name: "users"},
+			},
+			expErr: ErrModuleDuplicated,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// When
+			rs, err := SortModules(tc.modules)
+
+			// Then
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+
+			require.NoError(t, err)
+			order := make([]string, 0, len(rs))
+			for _, m := range rs {
+				order = append(order, m.Name())
+			}
+			require.Equal(t, tc.expOrder, order)
+		})
+	}
+}
@@ -2,54 +2,117 @@ package system
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
-	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/virsavik/alchemist-template/pkg/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
-	"golang.org/x/sync/errgroup"
-
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+
+	"github.com/virsavik/alchemist-template/data"
+	"github.com/virsavik/alchemist-template/pkg/admin"
+	"github.com/virsavik/alchemist-template/pkg/backoff"
+	"github.com/virsavik/alchemist-template/pkg/certs"
 	"github.com/virsavik/alchemist-template/pkg/config"
+	"github.com/virsavik/alchemist-template/pkg/di"
+	"github.com/virsavik/alchemist-template/pkg/events"
+	"github.com/virsavik/alchemist-template/pkg/health"
 	"github.com/virsavik/alchemist-template/pkg/iam/jwks"
 	"github.com/virsavik/alchemist-template/pkg/iam/validator"
+	"github.com/virsavik/alchemist-template/pkg/jobs"
 	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/metrics"
+	"github.com/virsavik/alchemist-template/pkg/migrate"
+	"github.com/virsavik/alchemist-template/pkg/otlp"
+	"github.com/virsavik/alchemist-template/pkg/outbox"
+	"github.com/virsavik/alchemist-template/pkg/postgres"
+	"github.com/virsavik/alchemist-template/pkg/rest/middleware"
+	"github.com/virsavik/alchemist-template/pkg/rpc/interceptor"
+	"github.com/virsavik/alchemist-template/pkg/scheduler"
 	"github.com/virsavik/alchemist-template/pkg/waiter"
 )
 
 // System represents the core components that acts as a Facade in the application, including its configuration,
-// database connection, HTTP request router, and a waiter for graceful shutdown. It serves
+// database connection, HTTP request router, dependency container and a waiter for graceful shutdown. It serves
 // as the central struct that encapsulates these essential elements for managing and
 // controlling the application's behavior.
 type System struct {
-	cfg       config.AppConfig
-	db        *sql.DB
-	mux       *chi.Mux
-	logger    logger.Logger
-	waiter    waiter.Waiter
-	tp        *sdktrace.TracerProvider
-	validator validator.Validator
+	cfg          config.AppConfig
+	offline      bool
+	db           *sql.DB
+	mux          *chi.Mux
+	web          *http.Server
+	routes       chi.Routes
+	admin        *http.Server
+	rpc          *grpc.Server
+	events       *events.Broker
+	scheduler    *scheduler.Scheduler
+	jobs         *jobs.Processor
+	health       health.Registry
+	logger       logger.Logger
+	levels       *logger.Levels
+	waiter       waiter.Waiter
+	tp           *sdktrace.TracerProvider
+	mp           *sdkmetric.MeterProvider
+	promRegistry *prometheus.Registry
+	validator    validator.Validator
+	container    di.Container
 }
 
-func New(cfg config.AppConfig) (*System, error) {
+const (
+	// certReloadInterval the interval the certificate files are checked for changes
+	certReloadInterval = time.Minute
+
+	// completedJobsRetention the time the completed jobs are kept before being purged
+	completedJobsRetention = 7 * 24 * time.Hour
+
+	// dispatchedEventsRetention the time the dispatched events are kept in the outbox before being purged
+	dispatchedEventsRetention = 7 * 24 * time.Hour
+)
+
+// Option configures the system
+type Option func(s *System)
+
+// Offline builds the system without connecting to the database nor migrating it, e.g. to inspect the
+// dependency graph of the modules. The connections are opened lazily, so that the system must not run.
+func Offline() Option {
+	return func(s *System) {
+		s.offline = true
+	}
+}
+
+func New(cfg config.AppConfig, opts ...Option) (*System, error) {
 	s := &System{cfg: cfg}
+	for _, opt := range opts {
+		opt(s)
+	}
 
 	s.initWaiter()
 
+	s.initHealth()
+
+	s.initLogger()
+
 	if err := s.initDB(); err != nil {
 		return nil, err
 	}
 
-	s.initLogger()
-
 	s.initMux()
 
 	if err := s.initValidator(); err != nil {
@@ -60,6 +123,30 @@ func New(cfg config.AppConfig) (*System, error) {
 		return nil, err
 	}
 
+	if err := s.initMetrics(); err != nil {
+		return nil, err
+	}
+
+	if err := s.initWeb(); err != nil {
+		return nil, err
+	}
+
+	s.initRPC()
+
+	s.initEvents()
+
+	s.initScheduler()
+
+	s.initOutbox()
+
+	s.initJobs()
+
+	s.initAdmin()
+
+	if err := s.initContainer(); err != nil {
+		return nil, err
+	}
+
 	return s, nil
 }
 
@@ -67,36 +154,97 @@ func (s *System) Config() config.AppConfig {
 	return s.cfg
 }
 
+// initDB initializes the connection pool of the database and connects to it, retrying until the
+// connect timeout so that the application does not fail while the database is starting
 func (s *System) initDB() (err error) {
-	s.db, err = sql.Open("pgx", s.cfg.PG.URI)
+	s.db, err = postgres.Open(s.cfg.PG)
+	if err != nil {
+		return err
+	}
 
-	s.waiter.Cleanup(func() {
-		s.logger.Infof("close db connection")
-		if err := s.db.Close(); err != nil {
-			s.logger.Errorf(err, "close db connection error")
+	if s.offline {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(s.waiter.Context(), s.cfg.PG.ConnectTimeout)
+	defer cancel()
+
+	if err = postgres.Connect(ctx, s.db, backoff.Exponential{Max: 5 * time.Second}, s.logger.Named("db")); err != nil {
+		_ = s.db.Close()
+		return err
+	}
+
+	if s.cfg.PG.AutoMigrate {
+		if err = s.migrateDB(); err != nil {
+			_ = s.db.Close()
+			return err
 		}
+	}
+
+	postgres.RegisterStats(s.db)
+
+	s.waiter.Cleanup(waiter.PhaseCloseStores, "db", func(ctx context.Context) error {
+		s.logger.Infof("close db connection")
+		return s.db.Close()
 	})
-	return err
+
+	s.health.Register("db", health.Readiness, health.Ping(s.db), health.CacheTTL(5*time.Second))
+
+	return nil
+}
+
+// migrateDB applies the pending migrations embedded into the binary, the replicas starting at once apply
+// them one at once
+func (s *System) migrateDB() error {
+	m, err := migrate.New(s.db, data.Migrations(), migrate.WithLogger(s.logger.Named("migrate")))
+	if err != nil {
+		return err
+	}
+
+	return m.Up(s.waiter.Context(), 0)
 }
 
 func (s *System) DB() *sql.DB {
 	return s.db
 }
 
+// initLogger initializes the root logger, the levels of the named loggers are changed at runtime
+// from the admin server or with the SIGUSR1 and SIGUSR2 signals
 func (s *System) initLogger() {
+	level := s.cfg.Log.Level
+	if level == "" {
+		level = logger.DefaultLevel(s.cfg.Environment)
+	}
+
 	var err error
+	s.levels, err = logger.NewLevels(level)
+	if err != nil {
+		panic("init logger levels error")
+	}
+
+	var sampling *logger.Sampling
+	if s.cfg.Log.SamplingInitial > 0 {
+		sampling = &logger.Sampling{
+			Initial:    s.cfg.Log.SamplingInitial,
+			Thereafter: s.cfg.Log.SamplingThereafter,
+		}
+	}
+
 	s.logger, err = logger.New(logger.Config{
 		Environment: s.cfg.Environment,
+		Levels:      s.levels,
+		Sampling:    sampling,
 	})
 	if err != nil {
 		panic("init logger error")
 	}
 
-	s.waiter.Cleanup(func() {
-		s.logger.Infof("flush logger")
-		if err := s.logger.Flush(); err != nil {
-			s.logger.Errorf(err, "flush logger error")
-		}
+	s.waiter.Add(func(ctx context.Context) error {
+		return s.levels.WatchSignals(ctx, s.cfg.Log.DebugTTL)
+	}, waiter.TaskName("log level signals"))
+
+	s.waiter.Cleanup(waiter.PhaseFlushTelemetry, "logger", func(ctx context.Context) error {
+		return s.logger.Flush()
 	})
 }
 
@@ -104,41 +252,56 @@ func (s *System) Logger() logger.Logger {
 	return s.logger
 }
 
+// initMux initializes the router of the modules with the middlewares of every request, in the order
+// they wrap the requests. The modules add their routes to their own routers, see Router.
 func (s *System) initMux() {
 	s.mux = chi.NewMux()
+	s.mux.Use(middleware.Logger(s.logger))
+	s.mux.Use(middleware.Recover())
+	s.mux.Use(middleware.OtelTracer())
+	s.mux.Use(middleware.OtelMetrics())
 }
 
-func (s *System) Mux() *chi.Mux {
-	return s.mux
+// Router returns a router mounted at the prefix, its requests go through the middlewares of the system
+// then the middlewares of the router. It panics when a router is already mounted at the prefix.
+//
+//	r := svc.Router("/users", middleware.Authenticator(svc.Validator()))
+//	r.Post("/", hdl.CreateUser())
+func (s *System) Router(prefix string, middlewares ...func(http.Handler) http.Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middlewares...)
+
+	s.mux.Mount(prefix, r)
+
+	return r
 }
 
 // initOpenTelemetry Initializes an OTLP exporter, and configures the corresponding trace
 // and metric providers.
 // copy from sample https://github.com/open-telemetry/opentelemetry-go/blob/main/example/otel-collector/main.go
 func (s *System) initOpenTelemetry() error {
-	// Skip when config is not provided
-	if s.cfg.Otel.ServiceName == "" || s.cfg.Otel.ExporterEndpoint == "" {
-		return nil
+	// set global propagator to tracecontext (the default is no-op).
+	otel.SetTextMapPropagator(
+		propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	)
+
+	// set logger to tracer
+	if s.logger != nil {
+		otel.SetErrorHandler(tracing.NewErrorHandler(s.Logger()))
 	}
 
 	ctx := context.Background()
 
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			// the service name used to display traces in backends
-			semconv.ServiceName(s.cfg.Otel.ServiceName),
-		),
-	)
+	// Set up the trace exporter selected by the config, skip when the traces are not exported
+	exporter, err := tracing.NewExporter(ctx, s.cfg.Otel)
+	if errors.Is(err, tracing.ErrNoExporter) {
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create resource: %w", err)
+		return err
 	}
 
-	// Set up a trace exporter
-	exporter, err := otlptracegrpc.New(
-		ctx,
-		otlptracegrpc.WithInsecure(),
-		otlptracegrpc.WithEndpoint(s.cfg.Otel.ExporterEndpoint),
-	)
+	res, err := s.resource(ctx)
 	if err != nil {
 		return err
 	}
@@ -148,28 +311,78 @@ func (s *System) initOpenTelemetry() error {
 	s.tp = sdktrace.NewTracerProvider(
 		sdktrace.WithBatcher(exporter),
 		sdktrace.WithResource(res),
+		sdktrace.WithSampler(tracing.NewSampler(s.cfg.Otel.SamplerRatio)),
 	)
 	otel.SetTracerProvider(s.tp)
 
-	// set global propagator to tracecontext (the default is no-op).
-	otel.SetTextMapPropagator(
-		propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
-	)
+	s.waiter.Cleanup(waiter.PhaseFlushTelemetry, "tracer provider", func(ctx context.Context) error {
+		return s.tp.Shutdown(ctx)
+	})
 
-	// set logger to tracer
-	if s.logger != nil {
-		otel.SetErrorHandler(tracing.NewErrorHandler(s.Logger()))
+	// The traces are buffered while the exporter is unreachable, it does not prevent serving traffic
+	if s.cfg.Otel.TracesExporter == config.TracesExporterOTLP {
+		s.health.Register("tracer exporter", health.Readiness, health.Dial(s.cfg.Otel.ExporterEndpoint),
+			health.Optional(), health.CacheTTL(30*time.Second))
 	}
 
-	s.waiter.Cleanup(func() {
-		if err := s.tp.Shutdown(context.Background()); err != nil {
-			s.logger.Errorf(err, "ran into an issue shutting down the tracer provider")
+	return nil
+}
+
+// initMetrics initializes the meter provider, the metrics are pulled from the /metrics endpoint in
+// the Prometheus format and pushed to the OTLP exporter when its endpoint is configured
+func (s *System) initMetrics() error {
+	ctx := context.Background()
+
+	res, err := s.resource(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.promRegistry = prometheus.NewRegistry()
+	promExporter, err := otelprom.New(otelprom.WithRegisterer(s.promRegistry))
+	if err != nil {
+		return fmt.Errorf("failed to create prometheus exporter: %w", err)
+	}
+
+	opts := []sdkmetric.Option{
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(promExporter),
+		// Aggregate the durations in seconds in buckets matching the latencies of a web service
+		sdkmetric.WithView(sdkmetric.NewView(
+			sdkmetric.Instrument{Kind: sdkmetric.InstrumentKindHistogram, Unit: "s"},
+			sdkmetric.Stream{Aggregation: sdkmetric.AggregationExplicitBucketHistogram{Boundaries: metrics.SecondsBuckets}},
+		)),
+	}
+
+	if s.cfg.Otel.ExporterEndpoint != "" {
+		otlpExporter, err := otlp.NewMetricExporter(ctx, s.cfg.Otel)
+		if err != nil {
+			return err
 		}
+
+		opts = append(opts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(otlpExporter)))
+	}
+
+	s.mp = sdkmetric.NewMeterProvider(opts...)
+	otel.SetMeterProvider(s.mp)
+
+	// Go runtime metrics: memory, garbage collections, goroutines
+	if err := runtime.Start(runtime.WithMeterProvider(s.mp), runtime.WithMinimumReadMemStatsInterval(time.Second)); err != nil {
+		return fmt.Errorf("failed to start runtime metrics: %w", err)
+	}
+
+	s.waiter.Cleanup(waiter.PhaseFlushTelemetry, "meter provider", func(ctx context.Context) error {
+		return s.mp.Shutdown(ctx)
 	})
 
 	return nil
 }
 
+// resource describes the application in the telemetry
+func (s *System) resource(ctx context.Context) (*resource.Resource, error) {
+	return tracing.NewResource(ctx, s.cfg)
+}
+
 func (s *System) initValidator() error {
 	// Skip initialize iam validator if config not provided
 	if s.cfg.IAM.Tenant == "" || s.cfg.IAM.Audience == "" {
@@ -187,10 +400,10 @@ func (s *System) initValidator() error {
 
 	s.validator = validator.New(parser)
 
-	// Add waiter for fetch jwks in goroutine
-	s.Waiter().Add(func(ctx context.Context) error {
-		return parser.FetchLoop(ctx)
-	})
+	// Add waiter for fetch jwks, restarted when the keys cannot be fetched
+	s.Waiter().Add(parser.FetchLoop, waiter.TaskName("jwks fetch loop"), waiter.Restart(waiter.RestartOnFailure, 0))
+
+	s.health.Register("jwks", health.Readiness, parser.Check)
 
 	return nil
 }
@@ -199,39 +412,333 @@ func (s *System) Validator() validator.Validator {
 	return s.validator
 }
 
+// initContainer initializes the root dependency container with the system components, the modules
+// register their dependencies into it and consume the dependencies exported by the other modules
+func (s *System) initContainer() error {
+	s.container = di.New()
+
+	di.AddSingleton(s.container, ConfigKey, func(c di.Container) (config.AppConfig, error) {
+		return s.cfg, nil
+	})
+	di.AddSingleton(s.container, DBKey, func(c di.Container) (*sql.DB, error) {
+		return s.db, nil
+	})
+	di.AddSingleton(s.container, LoggerKey, func(c di.Container) (logger.Logger, error) {
+		return s.logger, nil
+	})
+	di.AddSingleton(s.container, ValidatorKey, func(c di.Container) (validator.Validator, error) {
+		return s.validator, nil
+	})
+	di.AddSingleton(s.container, WaiterKey, func(c di.Container) (waiter.Waiter, error) {
+		return s.waiter, nil
+	})
+	di.AddSingleton(s.container, EventsKey, func(c di.Container) (events.Bus, error) {
+		return s.events, nil
+	})
+	di.AddSingleton(s.container, SchedulerKey, func(c di.Container) (scheduler.Registry, error) {
+		return s.scheduler, nil
+	})
+	di.AddSingleton(s.container, JobsKey, func(c di.Container) (jobs.Queue, error) {
+		return s.jobs, nil
+	})
+
+	// Request scoped transaction used by the unit of work middleware
+	postgres.RegisterTx(s.container, s.db)
+
+	di.DisposeOnCleanup(s.waiter, s.container, s.logger)
+
+	return s.container.Validate()
+}
+
+func (s *System) Container() di.Container {
+	return s.container
+}
+
 func (s *System) initWaiter() {
-	s.waiter = waiter.New(waiter.CatchSignals())
+	s.waiter = waiter.New(waiter.CatchSignals(), waiter.ShutdownTimeout(s.cfg.ShutdownTimeout))
+}
+
+// initHealth initializes the health check registry, the readiness fails as soon as the shutdown
+// starts and the servers keep serving the traffic for the drain delay
+func (s *System) initHealth() {
+	s.health = health.New()
+
+	s.waiter.Cleanup(waiter.PhasePreStop, "readiness", func(ctx context.Context) error {
+		s.health.ShutDown()
+
+		select {
+		case <-time.After(s.cfg.ShutdownDrainDelay):
+		case <-ctx.Done():
+		}
+
+		return nil
+	})
+}
+
+func (s *System) Health() health.Registry {
+	return s.health
 }
 
 func (s *System) Waiter() waiter.Waiter {
 	return s.waiter
 }
 
-func (s *System) WaitForWeb(ctx context.Context) error {
-	webServer := &http.Server{
-		Addr:    s.cfg.Web.Address(),
-		Handler: s.mux,
+// initWeb initializes the web server, it stops accepting new requests once the load balancers
+// stopped sending traffic and the in-flight requests are drained before the workers are stopped
+// and the stores are closed. The health checks and the metrics are served aside the routes of the
+// modules, out of their middlewares.
+//
+// The requests are served over TLS when a certificate is configured, the certificate is reloaded when
+// its files change. Otherwise, HTTP/2 is served over plain text connections when h2c is enabled.
+func (s *System) initWeb() error {
+	front := chi.NewRouter()
+	front.Get("/healthz", health.Handler(s.health, health.Liveness))
+	front.Get("/readyz", health.Handler(s.health, health.Readiness))
+	front.Handle("/metrics", promhttp.HandlerFor(s.promRegistry, promhttp.HandlerOpts{}))
+	front.Mount("/", s.mux)
+	s.routes = front
+
+	var handler http.Handler = front
+	if s.cfg.Web.H2C && !s.cfg.Web.TLS() {
+		handler = h2c.NewHandler(front, &http2.Server{IdleTimeout: s.cfg.Web.IdleTimeout})
 	}
 
-	group, gCtx := errgroup.WithContext(ctx)
-	group.Go(func() error {
-		fmt.Printf("web server started; listening at http://localhost%s\n", s.cfg.Web.Port)
-		defer fmt.Println("web server shutdown")
-		if err := webServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+	s.web = &http.Server{
+		Addr:              s.cfg.Web.Address(),
+		Handler:           handler,
+		ReadHeaderTimeout: s.cfg.Web.ReadHeaderTimeout,
+		ReadTimeout:       s.cfg.Web.ReadTimeout,
+		WriteTimeout:      s.cfg.Web.WriteTimeout,
+		IdleTimeout:       s.cfg.Web.IdleTimeout,
+		MaxHeaderBytes:    s.cfg.Web.MaxHeaderBytes,
+	}
+
+	if s.cfg.Web.TLS() {
+		reloader, err := certs.NewReloader(s.cfg.Web.TLSCertFile, s.cfg.Web.TLSKeyFile, s.logger.Named("certs"))
+		if err != nil {
 			return err
 		}
-		return nil
-	})
-	group.Go(func() error {
-		<-gCtx.Done()
+
+		s.web.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+
+		s.waiter.Add(func(ctx context.Context) error {
+			return reloader.Watch(ctx, certReloadInterval)
+		}, waiter.TaskName("certificate reloader"))
+	}
+
+	s.waiter.Cleanup(waiter.PhaseStopTraffic, "web server", func(ctx context.Context) error {
 		fmt.Println("web server to be shutdown")
-		ctx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
-		defer cancel()
-		if err := webServer.Shutdown(ctx); err != nil {
+		return s.web.Shutdown(ctx)
+	})
+
+	return nil
+}
+
+// initAdmin initializes the admin server on its own listener, the profiles, the routes, the tasks and the
+// configuration are never served by the public listeners. It stops accepting new requests with the web
+// server so that the wait functions are done before the workers are stopped.
+func (s *System) initAdmin() {
+	r := chi.NewRouter()
+	r.Use(middleware.Recover())
+	admin.Mount(r, s.routes, s.waiter, s.cfg)
+
+	// Changing the loggers and the jobs is restricted to the authenticated operators, it is not served
+	// when the IAM is not configured
+	if s.validator == nil {
+		s.logger.Warnf("iam is not configured, the loggers and the jobs are not served by the admin server")
+	} else {
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.Authenticator(s.validator), middleware.RequireScope(s.cfg.Admin.Scope))
+			admin.MountLoggers(r, s.levels)
+			admin.MountJobs(r, s.jobs)
+		})
+	}
+
+	s.admin = &http.Server{
+		Addr:    s.cfg.Admin.Address(),
+		Handler: r,
+	}
+
+	s.waiter.Cleanup(waiter.PhaseStopTraffic, "admin server", func(ctx context.Context) error {
+		fmt.Println("admin server to be shutdown")
+		return s.admin.Shutdown(ctx)
+	})
+}
+
+func (s *System) WaitForAdmin(ctx context.Context) error {
+	fmt.Printf("admin server started; listening at http://%s\n", s.cfg.Admin.Address())
+	defer fmt.Println("admin server shutdown")
+
+	if err := s.admin.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// initRPC initializes the gRPC server with the interceptors equivalent to the HTTP middlewares, every
+// request is authenticated. The server stops accepting new requests with the web server and waits
+// for the in-flight requests until the deadline of the shutdown phase.
+func (s *System) initRPC() {
+	s.rpc = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			interceptor.OtelTracer(),
+			interceptor.Logger(s.logger),
+			interceptor.Recover(),
+			interceptor.Authenticator(s.validator),
+		),
+	)
+
+	s.waiter.Cleanup(waiter.PhaseStopTraffic, "rpc server", func(ctx context.Context) error {
+		fmt.Println("rpc server to be shutdown")
+
+		stopped := make(chan struct{})
+		go func() {
+			s.rpc.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			s.rpc.Stop()
+			return ctx.Err()
+		}
+	})
+}
+
+func (s *System) RPC() grpc.ServiceRegistrar {
+	return s.rpc
+}
+
+func (s *System) WaitForRPC(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.cfg.RPC.Address())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("rpc server started; listening at %s\n", s.cfg.RPC.Address())
+	defer fmt.Println("rpc server shutdown")
+
+	if err := s.rpc.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+
+	return nil
+}
+
+// initEvents initializes the in-memory event broker, the events published by the modules are delivered
+// by WaitForStream. The broker is closed once the servers stopped, so that the events published by
+// their in-flight requests are delivered before the workers are stopped.
+func (s *System) initEvents() {
+	s.events = events.NewBroker(events.WithLogger(s.logger.Named("events")))
+
+	s.waiter.Cleanup(waiter.PhaseDrain, "event stream", func(ctx context.Context) error {
+		return s.events.Close(ctx)
+	})
+}
+
+func (s *System) Events() events.Bus {
+	return s.events
+}
+
+// initOutbox initializes the relay publishing the events written into the outbox to the event bus, it
+// stops polling once the waiter is done so that the relayed events are delivered by the event stream.
+// The relay runs on the leader replica only, so that the events are published in order. The dispatched
+// events are purged daily by the scheduler.
+func (s *System) initOutbox() {
+	log := s.logger.Named("outbox")
+	relay := outbox.NewRelay(s.db, s.events, outbox.WithLogger(log))
+
+	s.waiter.Add(postgres.Leader(s.db, "outbox relay", relay.Run, postgres.LeaderLogger(log)),
+		waiter.TaskName("outbox relay"),
+		waiter.Restart(waiter.RestartOnFailure, 0),
+	)
+
+	s.scheduler.Register("purge dispatched events", scheduler.MustCron("15 4 * * *"), func(ctx context.Context) error {
+		count, err := relay.Purge(ctx, time.Now().Add(-dispatchedEventsRetention))
+		if err != nil {
 			return err
 		}
+
+		logger.FromCtx(ctx).Infof("%d dispatched events purged", count)
+
 		return nil
-	})
+	}, scheduler.Jitter(time.Minute))
+}
+
+// initScheduler initializes the scheduler of the recurring jobs of the modules, it stops scheduling and
+// cancels the runs in progress once the waiter is done or the leadership is lost, the runs are waited with
+// the workers. The jobs are scheduled by the leader replica only, so that they do not run on every replica.
+func (s *System) initScheduler() {
+	log := s.logger.Named("scheduler")
+	s.scheduler = scheduler.New(log)
 
-	return group.Wait()
+	s.waiter.Add(postgres.Leader(s.db, "scheduler", s.scheduler.Run, postgres.LeaderLogger(log)),
+		waiter.TaskName("scheduler"),
+		waiter.Restart(waiter.RestartOnFailure, 0),
+	)
+	s.waiter.Cleanup(waiter.PhaseStopWorkers, "scheduler", s.scheduler.Close)
+}
+
+func (s *System) Scheduler() scheduler.Registry {
+	return s.scheduler
+}
+
+// initJobs initializes the processor of the job queue of the modules, it stops claiming jobs once the
+// waiter is done and the runs in progress are waited with the workers. The completed jobs are purged
+// daily by the scheduler.
+func (s *System) initJobs() {
+	s.jobs = jobs.NewProcessor(s.db, jobs.WithLogger(s.logger.Named("jobs")))
+
+	s.waiter.Add(s.jobs.Run, waiter.TaskName("job queue"), waiter.Restart(waiter.RestartOnFailure, 0))
+	s.waiter.Cleanup(waiter.PhaseStopWorkers, "job queue", s.jobs.Close)
+
+	s.scheduler.Register("purge completed jobs", scheduler.MustCron("0 4 * * *"), func(ctx context.Context) error {
+		count, err := s.jobs.Purge(ctx, time.Now().Add(-completedJobsRetention))
+		if err != nil {
+			return err
+		}
+
+		logger.FromCtx(ctx).Infof("%d completed jobs purged", count)
+
+		return nil
+	}, scheduler.Jitter(time.Minute))
+}
+
+func (s *System) Jobs() jobs.Queue {
+	return s.jobs
+}
+
+func (s *System) WaitForStream(ctx context.Context) error {
+	fmt.Println("event stream started")
+	defer fmt.Println("event stream shutdown")
+
+	return s.events.Run(ctx)
+}
+
+func (s *System) WaitForWeb(ctx context.Context) error {
+	if s.cfg.Web.TLS() {
+		fmt.Printf("web server started; listening at https://localhost%s\n", s.cfg.Web.Port)
+	} else {
+		fmt.Printf("web server started; listening at http://localhost%s\n", s.cfg.Web.Port)
+	}
+	defer fmt.Println("web server shutdown")
+
+	var err error
+	if s.cfg.Web.TLS() {
+		// The certificate is served by the TLS config
+		err = s.web.ListenAndServeTLS("", "")
+	} else {
+		err = s.web.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
 }
@@ -3,12 +3,19 @@ package system
 import (
 	"context"
 	"database/sql"
+	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
 
 	"github.com/virsavik/alchemist-template/pkg/config"
+	"github.com/virsavik/alchemist-template/pkg/di"
+	"github.com/virsavik/alchemist-template/pkg/events"
+	"github.com/virsavik/alchemist-template/pkg/health"
 	"github.com/virsavik/alchemist-template/pkg/iam/validator"
+	"github.com/virsavik/alchemist-template/pkg/jobs"
 	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/scheduler"
 	"github.com/virsavik/alchemist-template/pkg/waiter"
 )
 
@@ -16,13 +23,39 @@ import (
 type Service interface {
 	Config() config.AppConfig
 	DB() *sql.DB
-	Mux() *chi.Mux
+	Router(prefix string, middlewares ...func(http.Handler) http.Handler) chi.Router
+	RPC() grpc.ServiceRegistrar
 	Logger() logger.Logger
 	Waiter() waiter.Waiter
 	Validator() validator.Validator
+	Container() di.Container
+	Health() health.Registry
+	Events() events.Bus
+	Scheduler() scheduler.Registry
+	Jobs() jobs.Queue
 }
 
-// Module representing an application module
+// Module representing an application module, the name identifies the module in the dependencies of
+// the other modules, the logs and the traces
 type Module interface {
+	Name() string
 	Startup(context.Context, Service) error
 }
+
+// DependentModule a module started after the modules it depends on, and shut down before them
+type DependentModule interface {
+	Module
+	DependsOn() []string
+}
+
+// ShutdownModule a module releasing its resources on shutdown, e.g. stopping its background workers
+type ShutdownModule interface {
+	Module
+	Shutdown(context.Context) error
+}
+
+// ReadyModule a module reporting whether it is ready to serve, the module is checked by the readiness probe
+type ReadyModule interface {
+	Module
+	Ready(context.Context) error
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+
+	"github.com/virsavik/alchemist-template/pkg/config"
+	"github.com/virsavik/alchemist-template/pkg/otlp"
+)
+
+// ErrNoExporter is returned by NewExporter when the traces are not exported
+var ErrNoExporter = errors.New("traces exporter is none")
+
+// NewExporter creates the exporter of the traces selected by the configuration, it returns ErrNoExporter
+// when the traces are not exported
+func NewExporter(ctx context.Context, cfg config.OtelConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.TracesExporter {
+	case config.TracesExporterOTLP:
+		return otlp.NewTraceExporter(ctx, cfg)
+	case config.TracesExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case config.TracesExporterFile:
+		f, err := os.OpenFile(cfg.TracesFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open traces file: %w", err)
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+
+		return closingExporter{SpanExporter: exporter, closer: f}, nil
+	case config.TracesExporterNone, "":
+		return nil, ErrNoExporter
+	default:
+		return nil, fmt.Errorf("unknown traces exporter `%s`", cfg.TracesExporter)
+	}
+}
+
+// NewSampler samples the ratio of the root traces, the traces of the sampled remote parents are sampled
+// so that a trace is not broken across the services
+func NewSampler(ratio float64) sdktrace.Sampler {
+	return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))
+}
+
+// NewResource creates the resource of the application, describing the service, its environment and
+// its host
+func NewResource(ctx context.Context, cfg config.AppConfig) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{
+		semconv.DeploymentEnvironment(cfg.Environment),
+	}
+	if cfg.Otel.ServiceName != "" {
+		// the service name used to display traces in backends
+		attrs = append(attrs, semconv.ServiceName(cfg.Otel.ServiceName))
+	}
+	if cfg.Otel.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersion(cfg.Otel.ServiceVersion))
+	}
+	for k, v := range cfg.Otel.ResourceAttributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithHost(),
+		resource.WithProcessRuntimeName(),
+		resource.WithProcessRuntimeVersion(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attrs...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	return res, nil
+}
+
+// closingExporter closes the file of the exporter on shutdown
+type closingExporter struct {
+	sdktrace.SpanExporter
+	closer io.Closer
+}
+
+func (e closingExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.SpanExporter.Shutdown(ctx), e.closer.Close())
+}
@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/virsavik/alchemist-template/pkg/config"
+)
+
+func TestNewExporter(t *testing.T) {
+	tcs := map[string]struct {
+		cfg    config.OtelConfig
+		expErr error
+	}{
+		"none": {
+			cfg:    config.OtelConfig{TracesExporter: config.TracesExporterNone},
+			expErr: ErrNoExporter,
+		},
+		"stdout": {
+			cfg: config.OtelConfig{TracesExporter: config.TracesExporterStdout},
+		},
+		"otlp grpc": {
+			cfg: config.OtelConfig{
+				TracesExporter:   config.TracesExporterOTLP,
+				ExporterEndpoint: "localhost:4317",
+				ExporterProtocol: config.OTLPProtocolGRPC,
+				ExporterInsecure: true,
+			},
+		},
+		"otlp http with tls": {
+			cfg: config.OtelConfig{
+				TracesExporter:   config.TracesExporterOTLP,
+				ExporterEndpoint: "localhost:4318",
+				ExporterProtocol: config.OTLPProtocolHTTP,
+				ExporterHeaders:  map[string]string{"api-key": "secret"},
+			},
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// When
+			exporter, err := NewExporter(context.Background(), tc.cfg)
+
+			// Then
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NoError(t, exporter.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestNewExporter_File(t *testing.T) {
+	// Given
+	file := filepath.Join(t.TempDir(), "traces.json")
+	exporter, err := NewExporter(context.Background(), config.OtelConfig{
+		TracesExporter: config.TracesExporterFile,
+		TracesFile:     file,
+	})
+	require.NoError(t, err)
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	// When
+	_, span := tp.Tracer("test").Start(context.Background(), "create user")
+	span.End()
+	require.NoError(t, tp.Shutdown(context.Background()))
+
+	// Then
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Contains(t, string(content), `"Name":"create user"`)
+}
@@ -2,6 +2,7 @@ package waiter
 
 import (
 	"context"
+	"time"
 )
 
 type Option func(c *waiterCfg)
@@ -17,3 +18,13 @@ func CatchSignals() Option {
 		c.catchSignals = true
 	}
 }
+
+// ShutdownTimeout sets the time the shutdown phases have to complete, the remaining time is shared
+// equally between the remaining phases, so a phase completing early leaves more time to the next ones
+func ShutdownTimeout(timeout time.Duration) Option {
+	return func(c *waiterCfg) {
+		if timeout > 0 {
+			c.shutdownTimeout = timeout
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package waiter
+
+import (
+	"fmt"
+)
+
+// Phase a shutdown phase, the cleanup functions run phase by phase in the order below, the
+// functions of the same phase run concurrently
+type Phase int
+
+const (
+	// PhasePreStop prepares the stop while still serving traffic, e.g. failing the readiness checks
+	// and waiting for the load balancers to stop sending new traffic
+	PhasePreStop Phase = iota + 1
+	// PhaseStopTraffic stops accepting new traffic, e.g. shutting down the servers
+	PhaseStopTraffic
+	// PhaseDrain waits for the in-flight work to complete, the wait functions are waited in this phase
+	PhaseDrain
+	// PhaseStopWorkers stops the background workers, e.g. shutting down the modules and waiting for the
+	// jobs in progress
+	PhaseStopWorkers
+	// PhaseDispose disposes the dependencies once no worker uses them anymore
+	PhaseDispose
+	// PhaseFlushTelemetry flushes the buffered logs, traces and metrics
+	PhaseFlushTelemetry
+	// PhaseCloseStores closes the connections to the databases and the other stores
+	PhaseCloseStores
+)
+
+// phases in shutdown order
+var phases = []Phase{
+	PhasePreStop,
+	PhaseStopTraffic,
+	PhaseDrain,
+	PhaseStopWorkers,
+	PhaseDispose,
+	PhaseFlushTelemetry,
+	PhaseCloseStores,
+}
+
+func (p Phase) String() string {
+	switch p {
+	case PhasePreStop:
+		return "pre stop"
+	case PhaseStopTraffic:
+		return "stop traffic"
+	case PhaseDrain:
+		return "drain"
+	case PhaseStopWorkers:
+		return "stop workers"
+	case PhaseDispose:
+		return "dispose"
+	case PhaseFlushTelemetry:
+		return "flush telemetry"
+	case PhaseCloseStores:
+		return "close stores"
+	default:
+		return fmt.Sprintf("Phase(%d)", int(p))
+	}
+}
@@ -0,0 +1,61 @@
+package waiter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Report representing the result of a shutdown
+type Report struct {
+	Duration time.Duration
+	Phases   []PhaseReport
+}
+
+// PhaseReport representing the result of a shutdown phase
+type PhaseReport struct {
+	Phase    Phase
+	Timeout  time.Duration
+	Duration time.Duration
+	Tasks    []TaskReport
+}
+
+// TaskReport representing the result of a cleanup function
+type TaskReport struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// Err returns the aggregated errors of the cleanup functions
+func (r Report) Err() error {
+	var errs []error
+	for _, p := range r.Phases {
+		for _, t := range p.Tasks {
+			if t.Err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s: %w", p.Phase, t.Name, t.Err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r Report) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "shutdown completed in %s\n", r.Duration)
+	for _, p := range r.Phases {
+		fmt.Fprintf(&sb, "  %s (timeout %s) took %s\n", p.Phase, p.Timeout, p.Duration)
+		for _, t := range p.Tasks {
+			status := "ok"
+			if t.Err != nil {
+				status = t.Err.Error()
+			}
+			fmt.Fprintf(&sb, "    - %s: %s (%s)\n", t.Name, status, t.Duration)
+		}
+	}
+
+	return sb.String()
+}
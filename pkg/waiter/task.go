@@ -0,0 +1,248 @@
+package waiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/virsavik/alchemist-template/pkg/backoff"
+)
+
+var errReturned = errors.New("returned before the waiter is done")
+
+// RestartPolicy tells when a supervised wait function is restarted after returning
+type RestartPolicy int
+
+const (
+	// RestartNever the wait function is not restarted, an error stops the waiter
+	RestartNever RestartPolicy = iota
+	// RestartOnFailure the wait function is restarted when it returns an error or panics
+	RestartOnFailure
+	// RestartAlways the wait function is restarted whenever it returns before the waiter is done
+	RestartAlways
+)
+
+func (p RestartPolicy) String() string {
+	switch p {
+	case RestartNever:
+		return "never"
+	case RestartOnFailure:
+		return "on-failure"
+	case RestartAlways:
+		return "always"
+	default:
+		return fmt.Sprintf("RestartPolicy(%d)", int(p))
+	}
+}
+
+// TaskState the state of a supervised wait function
+type TaskState int
+
+const (
+	TaskPending TaskState = iota
+	TaskRunning
+	// TaskBackingOff the wait function is waiting for the backoff delay before restarting
+	TaskBackingOff
+	TaskCompleted
+	TaskFailed
+)
+
+func (s TaskState) String() string {
+	switch s {
+	case TaskPending:
+		return "pending"
+	case TaskRunning:
+		return "running"
+	case TaskBackingOff:
+		return "backing off"
+	case TaskCompleted:
+		return "completed"
+	case TaskFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("TaskState(%d)", int(s))
+	}
+}
+
+// TaskStatus representing the status of a supervised wait function
+type TaskStatus struct {
+	Name     string
+	Policy   RestartPolicy
+	State    TaskState
+	Restarts int // total number of restarts
+	LastErr  error
+	Started  time.Time // start time of the current run
+}
+
+type TaskOption func(t *task)
+
+// TaskName names the wait function in the task statuses and errors
+func TaskName(name string) TaskOption {
+	return func(t *task) {
+		t.name = name
+	}
+}
+
+// Restart sets the restart policy of the wait function, it is restarted after an exponential backoff.
+// The waiter is stopped with the error of the function once it has been restarted more than maxRestarts
+// times in a row, 0 meaning restarting forever.
+func Restart(policy RestartPolicy, maxRestarts int) TaskOption {
+	return func(t *task) {
+		t.policy = policy
+		t.maxRestarts = maxRestarts
+	}
+}
+
+// RestartBackoff sets the backoff between the restarts of the wait function
+func RestartBackoff(b backoff.Exponential) TaskOption {
+	return func(t *task) {
+		t.backoff = b
+	}
+}
+
+// StableAfter sets the time a run of the wait function lasts before the count of restarts in a row is
+// reset, the max delay of the restart backoff by default
+func StableAfter(d time.Duration) TaskOption {
+	return func(t *task) {
+		t.stableAfter = d
+	}
+}
+
+// task supervises a wait function
+type task struct {
+	fn          WaitFunc
+	name        string
+	policy      RestartPolicy
+	maxRestarts int
+	backoff     backoff.Exponential
+	stableAfter time.Duration
+
+	mu     sync.Mutex
+	status TaskStatus
+}
+
+func newTask(fn WaitFunc, name string, opts []TaskOption) *task {
+	t := &task{fn: fn, name: name}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if t.stableAfter <= 0 {
+		t.stableAfter = t.backoff.MaxDelay()
+	}
+
+	t.status = TaskStatus{Name: t.name, Policy: t.policy, State: TaskPending}
+
+	return t
+}
+
+// run runs the wait function until it returns without being restarted, the count of restarts in
+// a row is reset once a run lasted longer than stableAfter, so that a function failing slowly still
+// backs off and is stopped after maxRestarts
+func (t *task) run(ctx context.Context) error {
+	failures := 0
+
+	for {
+		started := time.Now()
+		t.update(func(s *TaskStatus) {
+			s.State, s.Started = TaskRunning, started
+		})
+
+		err := t.call(ctx)
+
+		// The waiter is done, the function is not restarted
+		if ctx.Err() != nil {
+			if errors.Is(err, ctx.Err()) {
+				err = nil
+			}
+			t.stop(err)
+			return err
+		}
+
+		if !t.restarts(err) {
+			t.stop(err)
+			if err != nil {
+				return fmt.Errorf("wait function `%s` failed: %w", t.name, err)
+			}
+			return nil
+		}
+
+		if time.Since(started) >= t.stableAfter {
+			failures = 0
+		}
+
+		if t.maxRestarts > 0 && failures >= t.maxRestarts {
+			if err == nil {
+				err = errReturned
+			}
+			t.stop(err)
+			return fmt.Errorf("wait function `%s` failed after %d restarts: %w", t.name, failures, err)
+		}
+
+		t.update(func(s *TaskStatus) {
+			s.State, s.LastErr = TaskBackingOff, err
+		})
+
+		if t.backoff.Wait(ctx, failures) != nil {
+			t.stop(err)
+			return nil
+		}
+
+		failures++
+		t.update(func(s *TaskStatus) {
+			s.Restarts++
+		})
+	}
+}
+
+// call calls the wait function, recovering its panic as an error
+func (t *task) call(ctx context.Context) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			pErr, ok := p.(error)
+			if !ok {
+				pErr = fmt.Errorf("%v", p)
+			}
+			err = fmt.Errorf("panic: %w", pErr)
+		}
+	}()
+
+	return t.fn(ctx)
+}
+
+func (t *task) restarts(err error) bool {
+	switch t.policy {
+	case RestartOnFailure:
+		return err != nil
+	case RestartAlways:
+		return true
+	default:
+		return false
+	}
+}
+
+func (t *task) stop(err error) {
+	t.update(func(s *TaskStatus) {
+		s.State = TaskCompleted
+		if err != nil {
+			s.State, s.LastErr = TaskFailed, err
+		}
+	})
+}
+
+func (t *task) update(fn func(s *TaskStatus)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fn(&t.status)
+}
+
+func (t *task) Status() TaskStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.status
+}
@@ -7,8 +7,8 @@ import (
 // WaitFunc a function to wait for
 type WaitFunc func(ctx context.Context) error
 
-// CleanupFunc a function after waiting
-type CleanupFunc func()
+// CleanupFunc a function after waiting, ctx is done when the deadline of its shutdown phase is exceeded
+type CleanupFunc func(ctx context.Context) error
 
 // Waiter is an interface that allows you to manage and control a set of functions,
 // enabling you to add functions to wait for, specify cleanup functions to be executed
@@ -16,15 +16,22 @@ type CleanupFunc func()
 // underlying context and cancel function, which can be used to control the waiting
 // process and handle cancellations.
 type Waiter interface {
-	// Add a function to wait for
-	Add(fns ...WaitFunc)
+	// Add a function to wait for, the options set its name and its restart policy, by default it is
+	// not restarted and its error stops the waiter
+	Add(fn WaitFunc, opts ...TaskOption)
 
-	// Cleanup with a function after waiting
-	Cleanup(fns ...CleanupFunc)
+	// Tasks return the status of the added functions
+	Tasks() []TaskStatus
 
-	// Wait for all added functions to complete
+	// Cleanup with a named function in the shutdown phase after waiting
+	Cleanup(phase Phase, name string, fn CleanupFunc)
+
+	// Wait for all added functions to complete, then shut down phase by phase
 	Wait() error
 
+	// Report return the report of the shutdown, it is empty until Wait returns
+	Report() Report
+
 	// Context return the context
 	Context() context.Context
 
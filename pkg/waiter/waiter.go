@@ -2,30 +2,46 @@ package waiter
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
 
+const defaultShutdownTimeout = 30 * time.Second
+
 // waiter implementation of the Waiter interface
 type waiter struct {
-	ctx          context.Context
-	waitFuncs    []WaitFunc
-	cleanupFuncs []CleanupFunc
-	cancel       context.CancelFunc
+	ctx             context.Context
+	tasks           []*task
+	cleanups        map[Phase][]cleanup
+	cancel          context.CancelFunc
+	shutdownTimeout time.Duration
+	report          Report
+	mu              sync.Mutex
+}
+
+type cleanup struct {
+	name string
+	fn   CleanupFunc
 }
 
 type waiterCfg struct {
-	parentCtx    context.Context
-	catchSignals bool
+	parentCtx       context.Context
+	catchSignals    bool
+	shutdownTimeout time.Duration
 }
 
 func New(options ...Option) Waiter {
 	cfg := &waiterCfg{
-		parentCtx:    context.Background(),
-		catchSignals: false,
+		parentCtx:       context.Background(),
+		catchSignals:    false,
+		shutdownTimeout: defaultShutdownTimeout,
 	}
 
 	for _, option := range options {
@@ -33,8 +49,9 @@ func New(options ...Option) Waiter {
 	}
 
 	w := &waiter{
-		waitFuncs:    []WaitFunc{},
-		cleanupFuncs: []CleanupFunc{},
+		tasks:           []*task{},
+		cleanups:        map[Phase][]cleanup{},
+		shutdownTimeout: cfg.shutdownTimeout,
 	}
 
 	w.ctx, w.cancel = context.WithCancel(cfg.parentCtx)
@@ -45,37 +62,168 @@ func New(options ...Option) Waiter {
 	return w
 }
 
-// Add a function to wait for
-func (w *waiter) Add(fns ...WaitFunc) {
-	w.waitFuncs = append(w.waitFuncs, fns...)
+// Add a function to wait for, supervised according to the options
+func (w *waiter) Add(fn WaitFunc, opts ...TaskOption) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.tasks = append(w.tasks, newTask(fn, fmt.Sprintf("task-%d", len(w.tasks)), opts))
 }
 
-// Cleanup with a function after waiting
-func (w *waiter) Cleanup(fns ...CleanupFunc) {
-	w.cleanupFuncs = append(w.cleanupFuncs, fns...)
+// Tasks returns the status of the supervised functions in the order they are added
+func (w *waiter) Tasks() []TaskStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	rs := make([]TaskStatus, len(w.tasks))
+	for idx, t := range w.tasks {
+		rs[idx] = t.Status()
+	}
+
+	return rs
 }
 
-// Wait for all added functions to complete
-func (w *waiter) Wait() (err error) {
+// Cleanup with a named function in the shutdown phase after waiting
+func (w *waiter) Cleanup(phase Phase, name string, fn CleanupFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.cleanups[phase] = append(w.cleanups[phase], cleanup{name: name, fn: fn})
+}
+
+// Wait for all added functions to complete, the shutdown starts as soon as the context is done,
+// either canceled, interrupted by a signal or because a function failed without being restarted
+func (w *waiter) Wait() error {
 	g, ctx := errgroup.WithContext(w.ctx)
 
-	g.Go(func() error {
-		<-ctx.Done()
-		w.cancel()
-		return nil
+	w.mu.Lock()
+	for _, t := range w.tasks {
+		t := t // Avoid closure capture
+		g.Go(func() error { return t.run(ctx) })
+	}
+	w.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- g.Wait()
+	}()
+
+	<-ctx.Done()
+	w.cancel()
+
+	// The wait functions are expected to return once the context is done, they are waited
+	// after stopping the traffic, e.g. while the servers drain their in-flight requests
+	waitErrs := make(chan error, 1)
+	w.Cleanup(PhaseDrain, "wait functions", func(ctx context.Context) error {
+		select {
+		case err := <-done:
+			waitErrs <- err
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	})
 
-	for _, fn := range w.waitFuncs {
-		waitFunc := fn // Avoid closure capture
-		g.Go(func() error { return waitFunc(ctx) })
+	report := w.shutdown()
+
+	var waitErr error
+	select {
+	case waitErr = <-waitErrs:
+	default:
+	}
+
+	w.mu.Lock()
+	w.report = report
+	w.mu.Unlock()
+
+	return errors.Join(waitErr, report.Err())
+}
+
+// shutdown runs the cleanup functions phase by phase
+func (w *waiter) shutdown() Report {
+	w.mu.Lock()
+	cleanups := make(map[Phase][]cleanup, len(w.cleanups))
+	for phase, fns := range w.cleanups {
+		cleanups[phase] = fns
+	}
+	w.mu.Unlock()
+
+	started := time.Now()
+	deadline := started.Add(w.shutdownTimeout)
+
+	var pending []Phase
+	for _, phase := range phases {
+		if len(cleanups[phase]) > 0 {
+			pending = append(pending, phase)
+		}
 	}
 
-	for _, fn := range w.cleanupFuncs {
-		cleanupFunc := fn // Avoid closure capture
-		defer cleanupFunc()
+	report := Report{}
+	for idx, phase := range pending {
+		timeout := time.Until(deadline) / time.Duration(len(pending)-idx)
+		report.Phases = append(report.Phases, runPhase(phase, timeout, cleanups[phase]))
 	}
+	report.Duration = time.Since(started)
+
+	return report
+}
+
+// runPhase runs the cleanup functions of the phase concurrently, the functions which do not
+// complete before the timeout are reported as failed and left behind
+func runPhase(phase Phase, timeout time.Duration, cleanups []cleanup) PhaseReport {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	started := time.Now()
+
+	results := make(chan struct {
+		idx    int
+		report TaskReport
+	}, len(cleanups))
+
+	for idx, c := range cleanups {
+		idx, c := idx, c // Avoid closure capture
+		go func() {
+			taskStarted := time.Now()
+			err := c.fn(ctx)
+			results <- struct {
+				idx    int
+				report TaskReport
+			}{idx: idx, report: TaskReport{Name: c.name, Duration: time.Since(taskStarted), Err: err}}
+		}()
+	}
+
+	tasks := make([]TaskReport, len(cleanups))
+	completed := make([]bool, len(cleanups))
+
+	for remaining := len(cleanups); remaining > 0; remaining-- {
+		select {
+		case rs := <-results:
+			tasks[rs.idx] = rs.report
+			completed[rs.idx] = true
+		case <-ctx.Done():
+			for idx, c := range cleanups {
+				if !completed[idx] {
+					tasks[idx] = TaskReport{Name: c.name, Duration: time.Since(started), Err: ctx.Err()}
+				}
+			}
+			remaining = 0
+		}
+	}
+
+	return PhaseReport{
+		Phase:    phase,
+		Timeout:  timeout,
+		Duration: time.Since(started),
+		Tasks:    tasks,
+	}
+}
+
+func (w *waiter) Report() Report {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 
-	return g.Wait()
+	return w.report
 }
 
 func (w *waiter) Context() context.Context {
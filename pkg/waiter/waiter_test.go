@@ -0,0 +1,209 @@
+package waiter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/virsavik/alchemist-template/pkg/backoff"
+)
+
+func TestWait(t *testing.T) {
+	// Given
+	errClose := errors.New("close failed")
+	w := New(ShutdownTimeout(time.Second))
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, name)
+	}
+
+	// The worker returns once the traffic is stopped, like a server returning after its shutdown
+	stopped := make(chan struct{})
+	w.Add(func(ctx context.Context) error {
+		<-stopped
+		record("worker")
+		return nil
+	})
+	w.Cleanup(PhaseCloseStores, "db", func(ctx context.Context) error {
+		record("db")
+		return errClose
+	})
+	w.Cleanup(PhaseStopWorkers, "consumer", func(ctx context.Context) error {
+		record("consumer")
+		return nil
+	})
+	w.Cleanup(PhaseStopTraffic, "web server", func(ctx context.Context) error {
+		record("web server")
+		close(stopped)
+		return nil
+	})
+
+	// When
+	w.CancelFunc()()
+	err := w.Wait()
+
+	// Then
+	require.ErrorIs(t, err, errClose)
+	require.EqualError(t, err, "close stores: db: close failed")
+	require.Equal(t, []string{"web server", "worker", "consumer", "db"}, calls)
+
+	report := w.Report()
+	require.Len(t, report.Phases, 4)
+	require.Equal(t, PhaseStopTraffic, report.Phases[0].Phase)
+	require.Equal(t, PhaseDrain, report.Phases[1].Phase)
+	require.Equal(t, "wait functions", report.Phases[1].Tasks[0].Name)
+	require.Equal(t, PhaseCloseStores, report.Phases[3].Phase)
+}
+
+func TestWait_PhaseTimeout(t *testing.T) {
+	// Given
+	errFailed := errors.New("failed")
+	w := New(ShutdownTimeout(200 * time.Millisecond))
+	w.Add(func(ctx context.Context) error {
+		return errFailed
+	})
+	w.Cleanup(PhaseStopTraffic, "hanging", func(ctx context.Context) error {
+		select {}
+	})
+	w.Cleanup(PhaseCloseStores, "db", func(ctx context.Context) error {
+		return nil
+	})
+
+	// When
+	started := time.Now()
+	err := w.Wait()
+
+	// Then
+	require.Less(t, time.Since(started), time.Second)
+	require.ErrorIs(t, err, errFailed)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	report := w.Report()
+	require.Len(t, report.Phases, 3)
+	require.Equal(t, "hanging", report.Phases[0].Tasks[0].Name)
+	require.ErrorIs(t, report.Phases[0].Tasks[0].Err, context.DeadlineExceeded)
+	require.NoError(t, report.Phases[2].Tasks[0].Err)
+}
+
+func TestAdd_Restart(t *testing.T) {
+	errFailed := errors.New("failed")
+	fastBackoff := RestartBackoff(backoff.Exponential{Initial: time.Millisecond, Max: time.Millisecond})
+
+	tcs := map[string]struct {
+		opts        []TaskOption
+		fn          func(runs int) error
+		expErr      string
+		expState    TaskState
+		expRestarts int
+	}{
+		"never": {
+			fn: func(runs int) error {
+				return errFailed
+			},
+			expErr:      "wait function `task-0` failed: failed",
+			expState:    TaskFailed,
+			expRestarts: 0,
+		},
+		"on failure until success": {
+			opts: []TaskOption{TaskName("worker"), Restart(RestartOnFailure, 5), fastBackoff},
+			fn: func(runs int) error {
+				if runs < 3 {
+					return errFailed
+				}
+				return nil
+			},
+			expState:    TaskCompleted,
+			expRestarts: 2,
+		},
+		"on failure recovers panics": {
+			opts: []TaskOption{TaskName("worker"), Restart(RestartOnFailure, 2), fastBackoff},
+			fn: func(runs int) error {
+				panic("boom")
+			},
+			expErr:      "wait function `worker` failed after 2 restarts: panic: boom",
+			expState:    TaskFailed,
+			expRestarts: 2,
+		},
+		"on failure failing slowly": {
+			opts: []TaskOption{
+				TaskName("worker"),
+				Restart(RestartOnFailure, 2),
+				RestartBackoff(backoff.Exponential{Initial: time.Millisecond, Max: 100 * time.Millisecond}),
+			},
+			fn: func(runs int) error {
+				time.Sleep(20 * time.Millisecond)
+				return errFailed
+			},
+			expErr:      "wait function `worker` failed after 2 restarts: failed",
+			expState:    TaskFailed,
+			expRestarts: 2,
+		},
+		"on failure stable runs reset the restarts": {
+			opts: []TaskOption{TaskName("worker"), Restart(RestartOnFailure, 1), fastBackoff, StableAfter(10 * time.Millisecond)},
+			fn: func(runs int) error {
+				if runs < 3 {
+					time.Sleep(20 * time.Millisecond)
+					return errFailed
+				}
+				return nil
+			},
+			expState:    TaskCompleted,
+			expRestarts: 2,
+		},
+		"always": {
+			opts: []TaskOption{TaskName("worker"), Restart(RestartAlways, 3), fastBackoff},
+			fn: func(runs int) error {
+				return nil
+			},
+			expErr:      "wait function `worker` failed after 3 restarts: returned before the waiter is done",
+			expState:    TaskFailed,
+			expRestarts: 3,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			w := New(ShutdownTimeout(time.Second))
+			runs := 0
+			w.Add(func(ctx context.Context) error {
+				runs++
+				return tc.fn(runs)
+			}, tc.opts...)
+
+			// Stop the waiter once the task is done
+			w.Add(func(ctx context.Context) error {
+				for {
+					if s := w.Tasks()[0].State; s == TaskCompleted || s == TaskFailed {
+						w.CancelFunc()()
+						return nil
+					}
+					time.Sleep(time.Millisecond)
+				}
+			})
+
+			// When
+			err := w.Wait()
+
+			// Then
+			if tc.expErr != "" {
+				require.EqualError(t, err, tc.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			status := w.Tasks()[0]
+			require.Equal(t, tc.expState, status.State)
+			require.Equal(t, tc.expRestarts, status.Restarts)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/virsavik/alchemist-template/pkg/config"
 	"github.com/virsavik/alchemist-template/pkg/system"
+	"github.com/virsavik/alchemist-template/pkg/waiter"
 	"github.com/virsavik/alchemist-template/users"
 )
 
@@ -27,19 +28,21 @@ func run() (err error) {
 		return err
 	}
 
-	// call the module composition root
-	if err = users.Root(s.Waiter().Context(), s); err != nil {
+	if err = system.StartupModules(s.Waiter().Context(), s, users.Module{}); err != nil {
+		return err
+	}
+
+	if err = s.Container().Validate(); err != nil {
 		return err
 	}
 
 	fmt.Println("started users service")
 	defer fmt.Println("stopped users service")
 
-	s.Waiter().Add(
-		s.WaitForWeb,
-		//s.WaitForRPC,
-		//s.WaitForStream,
-	)
+	s.Waiter().Add(s.WaitForWeb, waiter.TaskName("web server"))
+	s.Waiter().Add(s.WaitForRPC, waiter.TaskName("rpc server"))
+	s.Waiter().Add(s.WaitForAdmin, waiter.TaskName("admin server"))
+	s.Waiter().Add(s.WaitForStream, waiter.TaskName("event stream"))
 
 	//go func() {
 	//	for {
@@ -50,5 +53,8 @@ func run() (err error) {
 	//	}
 	//}()
 
-	return s.Waiter().Wait()
+	err = s.Waiter().Wait()
+	fmt.Print(s.Waiter().Report())
+
+	return err
 }
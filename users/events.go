@@ -0,0 +1,32 @@
+package users
+
+import (
+	"context"
+
+	"github.com/virsavik/alchemist-template/pkg/events"
+	"github.com/virsavik/alchemist-template/pkg/outbox"
+	"github.com/virsavik/alchemist-template/pkg/postgres"
+	"github.com/virsavik/alchemist-template/users/internal/core/domain"
+)
+
+// UserCreatedTopic is the topic of the events published when a user is created
+//
+//	events.Subscribe(svc.Events(), users.UserCreatedTopic, "orders.create-cart", handler)
+var UserCreatedTopic = events.NewTopic[UserCreated]("users.user_created")
+
+// UserCreated representing the payload of the event published when a user is created
+type UserCreated struct {
+	User User
+}
+
+// eventPublisher writes the events of the users module into the outbox, within the transaction of
+// the changes they describe, the outbox relay publishes them to the event bus
+type eventPublisher struct {
+	db postgres.ContextExecutor
+}
+
+func (p eventPublisher) UserCreated(ctx context.Context, user domain.User) error {
+	return outbox.Write(ctx, postgres.Trace(postgres.ExecutorFromCtx(ctx, p.db)),
+		events.New(ctx, UserCreatedTopic, UserCreated{User: toUser(user)}),
+	)
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/virsavik/alchemist-template/pkg/postgres"
 )
 
@@ -13,3 +15,9 @@ func New(db postgres.ContextExecutor) *Repository {
 		db: db,
 	}
 }
+
+// executor returns the traced executor of the transaction bound to ctx, or of the db when
+// ctx is not within a transaction
+func (r Repository) executor(ctx context.Context) postgres.ContextExecutor {
+	return postgres.Trace(postgres.ExecutorFromCtx(ctx, r.db))
+}
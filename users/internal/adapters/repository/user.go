@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/volatiletech/null/v8"
@@ -41,7 +42,7 @@ func (r Repository) GetAll(ctx context.Context, input ports.GetUserInput) ([]dom
 	var total int64
 	if input.Pagination.WithTotal {
 		var err error
-		total, err = orm.Users(qms...).Count(ctx, r.db)
+		total, err = orm.Users(qms...).Count(ctx, r.executor(ctx))
 		if err != nil {
 			return nil, 0, errors.WithStack(err)
 		}
@@ -57,7 +58,7 @@ func (r Repository) GetAll(ctx context.Context, input ports.GetUserInput) ([]dom
 	}
 
 	// Exec the query
-	users, err := orm.Users(qms...).All(ctx, r.db)
+	users, err := orm.Users(qms...).All(ctx, r.executor(ctx))
 	if err != nil {
 		return nil, 0, errors.WithStack(err)
 	}
@@ -117,7 +118,7 @@ func (r Repository) Save(ctx context.Context, user domain.User) (domain.User, er
 	}
 
 	// Save to database
-	if err := userORM.Upsert(ctx, r.db, true,
+	if err := userORM.Upsert(ctx, r.executor(ctx), true,
 		[]string{orm.UserColumns.Email},
 		boil.Whitelist(orm.UserColumns.DeletedAt),
 		boil.Infer(),
@@ -145,3 +146,15 @@ func (r Repository) Delete(ctx context.Context, user domain.User) error {
 
 	return nil
 }
+
+func (r Repository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	// Delete permanently the users soft deleted before the given time
+	count, err := orm.Users(
+		orm.UserWhere.DeletedAt.LT(null.TimeFrom(deletedBefore)),
+	).DeleteAll(ctx, r.executor(ctx))
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	return count, nil
+}
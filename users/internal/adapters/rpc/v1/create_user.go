@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+
+	"github.com/virsavik/alchemist-template/users/internal/core/domain"
+	"github.com/virsavik/alchemist-template/users/userspb"
+)
+
+func (srv UserServer) CreateUser(ctx context.Context, req *userspb.CreateUserRequest) (*userspb.CreateUserResponse, error) {
+	// Validate request
+	email := strings.TrimSpace(req.GetEmail())
+	if email == "" {
+		return nil, errEmailCannotBeBlank
+	}
+
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, wrapInvalidArgumentError(err, "invalid_email")
+	}
+
+	// Create user
+	user, err := srv.svc.Create(ctx, domain.User{
+		Email: email,
+	})
+	if err != nil {
+		return nil, convertServiceError(err)
+	}
+
+	return &userspb.CreateUserResponse{User: toUserProto(user)}, nil
+}
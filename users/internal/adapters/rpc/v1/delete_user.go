@@ -0,0 +1,24 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/virsavik/alchemist-template/users/internal/core/domain"
+	"github.com/virsavik/alchemist-template/users/userspb"
+)
+
+func (srv UserServer) DeleteUser(ctx context.Context, req *userspb.DeleteUserRequest) (*userspb.DeleteUserResponse, error) {
+	// Validate user ID
+	if req.GetId() <= 0 {
+		return nil, errUserIDMustBeGreaterThanZero
+	}
+
+	// Delete user
+	if err := srv.svc.Delete(ctx, domain.User{
+		ID: req.GetId(),
+	}); err != nil {
+		return nil, convertServiceError(err)
+	}
+
+	return &userspb.DeleteUserResponse{}, nil
+}
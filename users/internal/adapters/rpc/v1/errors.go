@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/virsavik/alchemist-template/users/internal/core/services"
+)
+
+var (
+	errEmailCannotBeBlank          = status.Error(codes.InvalidArgument, "email cannot be blank")
+	errUserIDMustBeGreaterThanZero = status.Error(codes.InvalidArgument, "user id must be greater than zero")
+	errToTimeCannotBeforeFromTime  = status.Error(codes.InvalidArgument, "from time cannot before to time")
+)
+
+func convertServiceError(err error) error {
+	switch {
+	case errors.Is(err, services.EmailHasBeenUsed):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, services.UserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return err
+	}
+}
+
+func wrapInvalidArgumentError(err error, name string) error {
+	return status.Errorf(codes.InvalidArgument, "%s: %v", name, err)
+}
@@ -0,0 +1,25 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/virsavik/alchemist-template/users/internal/core/ports"
+	"github.com/virsavik/alchemist-template/users/userspb"
+)
+
+func (srv UserServer) GetUser(ctx context.Context, req *userspb.GetUserRequest) (*userspb.GetUserResponse, error) {
+	// Validate user ID
+	if req.GetId() <= 0 {
+		return nil, errUserIDMustBeGreaterThanZero
+	}
+
+	// Get user
+	user, err := srv.svc.GetOne(ctx, ports.GetUserInput{
+		ID: req.GetId(),
+	})
+	if err != nil {
+		return nil, convertServiceError(err)
+	}
+
+	return &userspb.GetUserResponse{User: toUserProto(user)}, nil
+}
@@ -0,0 +1,57 @@
+package v1
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+
+	"github.com/virsavik/alchemist-template/users/internal/core/ports"
+	"github.com/virsavik/alchemist-template/users/internal/pkg/pagination"
+	"github.com/virsavik/alchemist-template/users/userspb"
+)
+
+func (srv UserServer) ListUsers(ctx context.Context, req *userspb.ListUsersRequest) (*userspb.ListUsersResponse, error) {
+	input := ports.GetUserInput{
+		Email: strings.TrimSpace(req.GetEmail()),
+		CreatedAt: ports.Period{
+			From: fromTimestampProto(req.GetCreatedFrom()),
+			To:   fromTimestampProto(req.GetCreatedTo()),
+		},
+		Pagination: pagination.Input{
+			Page:      int(req.GetPage()),
+			Size:      int(req.GetSize()),
+			WithTotal: true,
+		},
+	}
+
+	// Validate request
+	if input.Email != "" {
+		if _, err := mail.ParseAddress(input.Email); err != nil {
+			return nil, wrapInvalidArgumentError(err, "invalid_email")
+		}
+	}
+
+	if input.CreatedAt.To.Before(input.CreatedAt.From) {
+		return nil, errToTimeCannotBeforeFromTime
+	}
+
+	if err := input.Pagination.IsValid(); err != nil {
+		return nil, wrapInvalidArgumentError(err, "invalid_pagination")
+	}
+
+	// Get users
+	users, total, err := srv.svc.GetAll(ctx, input)
+	if err != nil {
+		return nil, convertServiceError(err)
+	}
+
+	resp := &userspb.ListUsersResponse{
+		Users: make([]*userspb.User, len(users)),
+		Total: total,
+	}
+	for idx, user := range users {
+		resp.Users[idx] = toUserProto(user)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/virsavik/alchemist-template/users/internal/core/domain"
+	"github.com/virsavik/alchemist-template/users/internal/core/ports"
+	"github.com/virsavik/alchemist-template/users/userspb"
+)
+
+type UserServer struct {
+	userspb.UnimplementedUsersServiceServer
+	svc ports.UserService
+}
+
+var _ userspb.UsersServiceServer = (*UserServer)(nil)
+
+func NewUserServer(svc ports.UserService) *UserServer {
+	return &UserServer{
+		svc: svc,
+	}
+}
+
+func toUserProto(user domain.User) *userspb.User {
+	return &userspb.User{
+		Id:        user.ID,
+		Email:     user.Email,
+		CreatedAt: timestamppb.New(user.CreateAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+	}
+}
+
+// fromTimestampProto converts the timestamp, an unset timestamp is the zero time
+func fromTimestampProto(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+
+	return ts.AsTime()
+}
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/virsavik/alchemist-template/users/internal/core/domain"
 )
@@ -14,14 +15,33 @@ type UserRepository interface {
 	Save(ctx context.Context, user domain.User) (domain.User, error)
 
 	Delete(ctx context.Context, user domain.User) error
+
+	// Purge deletes permanently the users soft deleted before deletedBefore, returning their count
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
 
 type UserService interface {
 	GetAll(ctx context.Context, input GetUserInput) ([]domain.User, int64, error)
 
+	GetOne(ctx context.Context, input GetUserInput) (domain.User, error)
+
 	Create(ctx context.Context, user domain.User) (domain.User, error)
 
 	Update(ctx context.Context, user domain.User) (domain.User, error)
 
 	Delete(ctx context.Context, user domain.User) error
+
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
+// EventPublisher publishes the events of the users module to the other modules, it is called within
+// the transaction of the changes so that the events are published once the changes are committed
+type EventPublisher interface {
+	UserCreated(ctx context.Context, user domain.User) error
+}
+
+// Transactor runs functions within a single unit of work, the changes made by fn are committed
+// when it returns nil and rolled back when it returns an error
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
@@ -2,18 +2,23 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/virsavik/alchemist-template/users/internal/core/domain"
 	"github.com/virsavik/alchemist-template/users/internal/core/ports"
 )
 
 type UserService struct {
-	repo ports.UserRepository
+	repo   ports.UserRepository
+	tx     ports.Transactor
+	events ports.EventPublisher
 }
 
-func NewUserService(repo ports.UserRepository) *UserService {
+func NewUserService(repo ports.UserRepository, tx ports.Transactor, events ports.EventPublisher) *UserService {
 	return &UserService{
-		repo: repo,
+		repo:   repo,
+		tx:     tx,
+		events: events,
 	}
 }
 
@@ -26,23 +31,47 @@ func (svc UserService) GetAll(ctx context.Context, input ports.GetUserInput) ([]
 	return users, total, nil
 }
 
-func (svc UserService) Create(ctx context.Context, user domain.User) (domain.User, error) {
-	// Find user by email
-	selectedUser, err := svc.repo.GetOne(ctx, ports.GetUserInput{
-		Email: user.Email,
-	})
+func (svc UserService) GetOne(ctx context.Context, input ports.GetUserInput) (domain.User, error) {
+	user, err := svc.repo.GetOne(ctx, input)
 	if err != nil {
 		return domain.User{}, err
 	}
 
-	// Return error if user exists
-	if selectedUser.ID != 0 {
-		return domain.User{}, EmailHasBeenUsed
+	// Return error if user not exists
+	if user.ID == 0 {
+		return domain.User{}, UserNotFound
 	}
 
-	// Save user
-	createdUser, err := svc.repo.Save(ctx, user)
-	if err != nil {
+	return user, nil
+}
+
+func (svc UserService) Create(ctx context.Context, user domain.User) (domain.User, error) {
+	var createdUser domain.User
+
+	// Check and save within the same transaction
+	if err := svc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Find user by email
+		selectedUser, err := svc.repo.GetOne(ctx, ports.GetUserInput{
+			Email: user.Email,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Return error if user exists
+		if selectedUser.ID != 0 {
+			return EmailHasBeenUsed
+		}
+
+		// Save user
+		createdUser, err = svc.repo.Save(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		// Notify the other modules once the user is committed
+		return svc.events.UserCreated(ctx, createdUser)
+	}); err != nil {
 		return domain.User{}, err
 	}
 
@@ -50,46 +79,54 @@ func (svc UserService) Create(ctx context.Context, user domain.User) (domain.Use
 }
 
 func (svc UserService) Update(ctx context.Context, user domain.User) (domain.User, error) {
-	// Find user by email
-	selectedUser, err := svc.repo.GetOne(ctx, ports.GetUserInput{
-		Email: user.Email,
-	})
-	if err != nil {
-		return domain.User{}, err
-	}
-
-	// Return error if user not exists
-	if selectedUser.ID == 0 {
-		return domain.User{}, UserNotFound
-	}
-
-	// Save user
-	createdUser, err := svc.repo.Save(ctx, user)
-	if err != nil {
+	var updatedUser domain.User
+
+	// Check and save within the same transaction
+	if err := svc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Find user by email
+		selectedUser, err := svc.repo.GetOne(ctx, ports.GetUserInput{
+			Email: user.Email,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Return error if user not exists
+		if selectedUser.ID == 0 {
+			return UserNotFound
+		}
+
+		// Save user
+		updatedUser, err = svc.repo.Save(ctx, user)
+		return err
+	}); err != nil {
 		return domain.User{}, err
 	}
 
-	return createdUser, nil
+	return updatedUser, nil
 }
 
 func (svc UserService) Delete(ctx context.Context, user domain.User) error {
-	// Find user by email
-	selectedUser, err := svc.repo.GetOne(ctx, ports.GetUserInput{
-		ID: user.ID,
+	// Check and delete within the same transaction
+	return svc.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Find user by email
+		selectedUser, err := svc.repo.GetOne(ctx, ports.GetUserInput{
+			ID: user.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Return error if user not exists
+		if selectedUser.ID == 0 {
+			return UserNotFound
+		}
+
+		// Delete user
+		return svc.repo.Delete(ctx, selectedUser)
 	})
-	if err != nil {
-		return err
-	}
-
-	// Return error if user not exists
-	if selectedUser.ID == 0 {
-		return UserNotFound
-	}
-
-	// Delete user
-	if err := svc.repo.Delete(ctx, selectedUser); err != nil {
-		return err
-	}
+}
 
-	return nil
+func (svc UserService) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return svc.repo.Purge(ctx, deletedBefore)
 }
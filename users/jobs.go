@@ -0,0 +1,36 @@
+package users
+
+import (
+	"context"
+	"time"
+
+	"github.com/virsavik/alchemist-template/pkg/logger"
+	"github.com/virsavik/alchemist-template/pkg/scheduler"
+	"github.com/virsavik/alchemist-template/pkg/system"
+	"github.com/virsavik/alchemist-template/users/internal/core/ports"
+)
+
+// deletedRetention the time the soft deleted users are kept before being purged
+const deletedRetention = 30 * 24 * time.Hour
+
+func setupJobs(svc system.Service, userSvc ports.UserService) {
+	svc.Scheduler().Register("purge deleted users", scheduler.MustCron("30 3 * * *"),
+		purgeDeletedUsers(userSvc),
+		scheduler.Jitter(time.Minute),
+		scheduler.Timeout(10*time.Minute),
+	)
+}
+
+// purgeDeletedUsers deletes permanently the users soft deleted for longer than the retention
+func purgeDeletedUsers(userSvc ports.UserService) scheduler.JobFunc {
+	return func(ctx context.Context) error {
+		count, err := userSvc.Purge(ctx, time.Now().Add(-deletedRetention))
+		if err != nil {
+			return err
+		}
+
+		logger.FromCtx(ctx).Infof("%d deleted users purged", count)
+
+		return nil
+	}
+}
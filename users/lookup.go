@@ -0,0 +1,78 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/virsavik/alchemist-template/pkg/di"
+	"github.com/virsavik/alchemist-template/users/internal/core/domain"
+	"github.com/virsavik/alchemist-template/users/internal/core/ports"
+	"github.com/virsavik/alchemist-template/users/internal/core/services"
+)
+
+// LookupKey is the key of the users lookup service exported to the other modules
+//
+//	user, err := di.Resolve(svc.Container(), users.LookupKey).FindByID(ctx, id)
+var LookupKey = di.NewKey[Lookup]("users.lookup")
+
+var ErrUserNotFound = errors.New("user not found")
+
+// User representing a user exported to the other modules
+type User struct {
+	ID        int64
+	Email     string
+	CreatedAt time.Time
+}
+
+// Lookup finds the users for the other modules
+type Lookup interface {
+	FindByID(ctx context.Context, id int64) (User, error)
+
+	FindByEmail(ctx context.Context, email string) (User, error)
+}
+
+type lookup struct {
+	svc ports.UserService
+}
+
+// FindByID finds the user of the id, an id which is not positive is not found without querying since
+// an empty input would match any user
+func (l lookup) FindByID(ctx context.Context, id int64) (User, error) {
+	if id <= 0 {
+		return User{}, ErrUserNotFound
+	}
+
+	return l.find(ctx, ports.GetUserInput{ID: id})
+}
+
+// FindByEmail finds the user of the email, an empty email is not found without querying since an empty
+// input would match any user
+func (l lookup) FindByEmail(ctx context.Context, email string) (User, error) {
+	if strings.TrimSpace(email) == "" {
+		return User{}, ErrUserNotFound
+	}
+
+	return l.find(ctx, ports.GetUserInput{Email: email})
+}
+
+func (l lookup) find(ctx context.Context, input ports.GetUserInput) (User, error) {
+	user, err := l.svc.GetOne(ctx, input)
+	if err != nil {
+		if errors.Is(err, services.UserNotFound) {
+			return User{}, ErrUserNotFound
+		}
+		return User{}, err
+	}
+
+	return toUser(user), nil
+}
+
+func toUser(user domain.User) User {
+	return User{
+		ID:        user.ID,
+		Email:     user.Email,
+		CreatedAt: user.CreateAt,
+	}
+}
@@ -0,0 +1,72 @@
+package users
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/virsavik/alchemist-template/users/internal/core/domain"
+	"github.com/virsavik/alchemist-template/users/internal/core/ports"
+)
+
+// fakeUserService returns the user of the input, it fails the test when called with an empty input
+type fakeUserService struct {
+	ports.UserService
+	t *testing.T
+}
+
+func (s fakeUserService) GetOne(ctx context.Context, input ports.GetUserInput) (domain.User, error) {
+	require.False(s.t, input.ID == 0 && input.Email == "", "the service is called with an empty input")
+
+	return domain.User{ID: input.ID, Email: input.Email}, nil
+}
+
+func TestLookup_Find(t *testing.T) {
+	tcs := map[string]struct {
+		find   func(l Lookup) (User, error)
+		expRs  User
+		expErr error
+	}{
+		"by id": {
+			find:  func(l Lookup) (User, error) { return l.FindByID(context.Background(), 14) },
+			expRs: User{ID: 14},
+		},
+		"by zero id": {
+			find:   func(l Lookup) (User, error) { return l.FindByID(context.Background(), 0) },
+			expErr: ErrUserNotFound,
+		},
+		"by negative id": {
+			find:   func(l Lookup) (User, error) { return l.FindByID(context.Background(), -1) },
+			expErr: ErrUserNotFound,
+		},
+		"by email": {
+			find:  func(l Lookup) (User, error) { return l.FindByEmail(context.Background(), "john@example.com") },
+			expRs: User{Email: "john@example.com"},
+		},
+		"by empty email": {
+			find:   func(l Lookup) (User, error) { return l.FindByEmail(context.Background(), " ") },
+			expErr: ErrUserNotFound,
+		},
+	}
+
+	for desc, tc := range tcs {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			// Given
+			l := lookup{svc: fakeUserService{t: t}}
+
+			// When
+			rs, err := tc.find(l)
+
+			// Then
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expRs, rs)
+		})
+	}
+}
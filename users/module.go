@@ -3,19 +3,32 @@ package users
 import (
 	"context"
 
-	"github.com/go-chi/chi/v5"
-
+	"github.com/virsavik/alchemist-template/pkg/di"
 	"github.com/virsavik/alchemist-template/pkg/postgres"
 	"github.com/virsavik/alchemist-template/pkg/rest/middleware"
 	"github.com/virsavik/alchemist-template/pkg/system"
 	"github.com/virsavik/alchemist-template/users/internal/adapters/repository"
 	"github.com/virsavik/alchemist-template/users/internal/adapters/repository/generator"
 	v1 "github.com/virsavik/alchemist-template/users/internal/adapters/rest/v1"
+	rpcv1 "github.com/virsavik/alchemist-template/users/internal/adapters/rpc/v1"
+	"github.com/virsavik/alchemist-template/users/internal/core/ports"
 	"github.com/virsavik/alchemist-template/users/internal/core/services"
+	"github.com/virsavik/alchemist-template/users/userspb"
+)
+
+var (
+	userRepositoryKey = di.NewKey[ports.UserRepository]("users.repository")
+	userServiceKey    = di.NewKey[ports.UserService]("users.service")
+	userHandlerKey    = di.NewKey[*v1.UserHandler]("users.handler.v1")
+	userServerKey     = di.NewKey[*rpcv1.UserServer]("users.server.v1")
 )
 
 type Module struct{}
 
+func (m Module) Name() string {
+	return "users"
+}
+
 func (m Module) Startup(ctx context.Context, mono system.Service) (err error) {
 	return Root(ctx, mono)
 }
@@ -24,25 +37,49 @@ func Root(ctx context.Context, svc system.Service) (err error) {
 	// Init sonyflake id generator
 	generator.InitIDGenerator()
 
-	store := repository.New(postgres.Trace(svc.DB()))
-	userService := services.NewUserService(store)
-	userHandler := v1.NewUserHandler(userService)
+	ctn := svc.Container()
+
+	di.AddSingleton(ctn, userRepositoryKey, func(c di.Container) (ports.UserRepository, error) {
+		return repository.New(di.Resolve(c, system.DBKey)), nil
+	}, di.DependsOn(system.DBKey.String()))
+
+	di.AddSingleton(ctn, userServiceKey, func(c di.Container) (ports.UserService, error) {
+		return services.NewUserService(
+			di.Resolve(c, userRepositoryKey),
+			postgres.NewUnitOfWork(ctn),
+			eventPublisher{db: di.Resolve(c, system.DBKey)},
+		), nil
+	}, di.DependsOn(userRepositoryKey.String(), system.DBKey.String()))
 
-	setupRoutes(svc, *userHandler)
+	di.AddSingleton(ctn, userHandlerKey, func(c di.Container) (*v1.UserHandler, error) {
+		return v1.NewUserHandler(di.Resolve(c, userServiceKey)), nil
+	}, di.DependsOn(userServiceKey.String()))
+
+	di.AddSingleton(ctn, userServerKey, func(c di.Container) (*rpcv1.UserServer, error) {
+		return rpcv1.NewUserServer(di.Resolve(c, userServiceKey)), nil
+	}, di.DependsOn(userServiceKey.String()))
+
+	// Exported to the other modules
+	di.AddSingleton(ctn, LookupKey, func(c di.Container) (Lookup, error) {
+		return lookup{svc: di.Resolve(c, userServiceKey)}, nil
+	}, di.DependsOn(userServiceKey.String()))
+
+	setupRoutes(svc, *di.Resolve(ctn, userHandlerKey))
+
+	userspb.RegisterUsersServiceServer(svc.RPC(), di.Resolve(ctn, userServerKey))
+
+	setupJobs(svc, di.Resolve(ctn, userServiceKey))
 
 	return nil
 }
 
 func setupRoutes(svc system.Service, hdl v1.UserHandler) {
-	svc.Mux().Use(middleware.Logger(svc.Logger()))
-	svc.Mux().Use(middleware.Recover())
-	svc.Mux().Use(middleware.OtelTracer())
-
-	svc.Mux().Route("/users", func(v1 chi.Router) {
-		v1.Use(middleware.Authenticator(svc.Validator()))
+	r := svc.Router("/users",
+		middleware.Authenticator(svc.Validator()),
+		middleware.UnitOfWork(svc.Container()),
+	)
 
-		v1.Post("/", hdl.CreateUser())
-		v1.Get("/", hdl.GetUser())
-		v1.Delete("/{id}", hdl.DeleteUser())
-	})
+	r.Post("/", hdl.CreateUser())
+	r.Get("/", hdl.GetUser())
+	r.Delete("/{id}", hdl.DeleteUser())
 }
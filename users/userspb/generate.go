@@ -0,0 +1,3 @@
+package userspb
+
+//go:generate buf generate